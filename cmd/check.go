@@ -0,0 +1,65 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/joshuar/autocorrector/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formatFlag string
+	checkCmd   = &cobra.Command{
+		Use:   "check PATH...",
+		Short: "Report typos in files.",
+		Long: `Report the typos in the given files and directories that are in the corrections list.
+Directories are searched recursively, skipping files ignored by .gitignore and binary files.
+Exits with status 1 if any typos were found, or 2 if there was an error, such as
+a file that could not be read.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return checkError(cobra.MinimumNArgs(1)(cmd, args))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := corrections.NewCorrections(languages()...)
+			if err != nil {
+				return checkError(err)
+			}
+			typos, err := lint.Check(c, args...)
+			if err != nil {
+				return checkError(err)
+			}
+			if err := lint.WriteReport(cmd.OutOrStdout(), formatFlag, typos); err != nil {
+				return checkError(err)
+			}
+			if len(typos) > 0 {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+)
+
+// checkError makes check exit with status 2 on err, so that it can be told
+// apart from finding typos.
+func checkError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{err: err, code: 2}
+}
+
+func init() {
+	checkCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return checkError(err)
+	})
+	checkCmd.Flags().StringVarP(&formatFlag, "format", "f", "plain",
+		"report format ("+strings.Join(lint.Formats, ", ")+")")
+	rootCmd.AddCommand(checkCmd)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/joshuar/autocorrector/internal/config"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
@@ -44,3 +45,17 @@ func setProfiling() {
 		}()
 	}
 }
+
+// languages returns the languages whose corrections are used, from the
+// --language flag or, if it was not given, the config file.
+func languages() []string {
+	if len(languageFlag) > 0 {
+		return languageFlag
+	}
+	cfg, err := config.Load(config.Path)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not load config, using corrections for all languages.")
+		return nil
+	}
+	return cfg.Languages.Active
+}
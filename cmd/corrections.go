@@ -0,0 +1,250 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/convert"
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	correctionsCmd = &cobra.Command{
+		Use:   "corrections",
+		Short: "Manage the corrections list.",
+		Long: `Add, remove and look up corrections.
+Changes are made to the user's corrections file and are picked up by a running autocorrector automatically.`,
+	}
+	correctionsAddCmd = &cobra.Command{
+		Use:   "add TYPO CORRECTION",
+		Short: "Add a correction, or change an existing one.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return corrections.AddCorrection(args[0], args[1])
+		},
+	}
+	correctionsRemoveCmd = &cobra.Command{
+		Use:   "remove TYPO",
+		Short: "Remove a correction.",
+		Long: `Remove a correction.
+Corrections from other files, such as the system-wide list or drop-in files, are not edited. Instead, they are overridden by adding an empty correction to the user's corrections file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			overridden, err := corrections.RemoveCorrection(args[0])
+			if err != nil {
+				return err
+			}
+			if overridden != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s comes from %s, added an empty correction to the user's corrections file to override it.\n", args[0], overridden)
+			}
+			return nil
+		},
+	}
+	correctionsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all corrections.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := corrections.ListCorrections(languages()...)
+			if err != nil {
+				return err
+			}
+			printEntries(cmd.OutOrStdout(), entries)
+			return nil
+		},
+	}
+	correctionsSearchCmd = &cobra.Command{
+		Use:   "search TEXT",
+		Short: "List the corrections containing some text.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := corrections.SearchCorrections(args[0], languages()...)
+			if err != nil {
+				return err
+			}
+			printEntries(cmd.OutOrStdout(), entries)
+			return nil
+		},
+	}
+	wordListFlag       string
+	correctionsLintCmd = &cobra.Command{
+		Use:   "lint [FILE...]",
+		Short: "Report problems with corrections files.",
+		Long: `Report entries in the given corrections files, or all corrections files for the active languages if none are given, that are mistakes or can never be used.
+This includes typos that are their own correction, typos containing punctuation or symbols, corrections that are themselves corrected, typos differing only in case, typos that override another file and typos that are dictionary words.
+Exits with status 1 if any problems were found.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				files = corrections.LayerFiles(languages()...)
+			}
+			wordList := wordListFlag
+			if wordList == "" {
+				cfg, err := config.Load(config.Path)
+				if err != nil {
+					return err
+				}
+				wordList = cfg.SpellCheck.WordList
+			}
+			dictionary, err := corrections.NewSpellChecker(wordList, 0, 0)
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not load word list, dictionary words will not be reported.")
+			}
+			problems, err := corrections.Lint(files, dictionary)
+			if err != nil {
+				return err
+			}
+			for _, p := range problems {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %s: %s\n", p.File, p.Typo, p.Message)
+			}
+			if len(problems) > 0 {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	importFormatFlag     string
+	outputFlag           string
+	correctionsImportCmd = &cobra.Command{
+		Use:   "import PATH",
+		Short: "Convert corrections from another autocorrect tool.",
+		Long: `Convert the corrections from another autocorrect tool to a corrections file.
+Supported formats are AutoHotkey hotstrings (autohotkey), Autokey phrase folders (autokey), codespell dictionaries (codespell), LibreOffice and Microsoft Office DocumentList.xml or acor_*.dat files (documentlist) and Espanso match files (espanso).
+The format is guessed from the file name unless --format is given.
+The corrections file is written to standard output, or to the file given with --output, such as a file in $HOME/.config/autocorrector/corrections.d.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := importFormatFlag
+			if format == "" {
+				var err error
+				if format, err = convert.DetectFormat(args[0]); err != nil {
+					return err
+				}
+			}
+			imported, err := convert.Import(format, args[0])
+			if err != nil {
+				return err
+			}
+			b, err := convert.MarshalTOML(imported)
+			if err != nil {
+				return err
+			}
+			b = append([]byte(fmt.Sprintf("# Imported from %s (%s).\n\n", args[0], format)), b...)
+			log.Info().Str("format", format).Int("corrections", len(imported)).Msg("Imported corrections.")
+			if outputFlag != "" {
+				return os.WriteFile(outputFlag, b, 0o640)
+			}
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+	exportFormatFlag     string
+	correctionsExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Convert the corrections list for another tool.",
+		Long: `Convert the corrections list to the format of another tool.
+Supported formats are AutoHotkey hotstrings (autohotkey), a codespell dictionary (codespell), an Espanso match file (espanso), a JSON array (json) and Vim abbreviations (vim).
+Corrections that the format cannot represent, such as phrases for codespell and Vim, are skipped.
+The converted list is written to standard output, or to the file given with --output.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := corrections.ListCorrections(languages()...)
+			if err != nil {
+				return err
+			}
+			if outputFlag == "" {
+				return convert.Export(cmd.OutOrStdout(), exportFormatFlag, entries)
+			}
+			f, err := os.Create(outputFlag)
+			if err != nil {
+				return err
+			}
+			if err := convert.Export(f, exportFormatFlag, entries); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+	correctionsLanguagesCmd = &cobra.Command{
+		Use:   "languages",
+		Short: "List the languages there are corrections for.",
+		Long: `List the languages that there are corrections files for, such as corrections.de.toml.
+Languages whose corrections are used are marked with an asterisk.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			active := make(map[string]bool)
+			for _, lang := range languages() {
+				active[lang] = true
+			}
+			for _, lang := range corrections.AvailableLanguages() {
+				marker := " "
+				if active[lang] || len(active) == 0 {
+					marker = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, lang)
+			}
+			return nil
+		},
+	}
+	correctionsShowCmd = &cobra.Command{
+		Use:   "show WORD",
+		Short: "Show the correction for a word and the file it comes from.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := corrections.ShowCorrection(args[0], languages()...)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s -> %s\n", args[0], e.Correction)
+			fmt.Fprintf(out, "Defined as %q in %s", e.Typo, e.File)
+			switch {
+			case e.Exact:
+				fmt.Fprint(out, " (exact)")
+			case e.Verbatim:
+				fmt.Fprint(out, " (verbatim)")
+			}
+			fmt.Fprintln(out)
+			if len(e.Apps) > 0 {
+				fmt.Fprintf(out, "Only in %s\n", strings.Join(e.Apps, ", "))
+			}
+			if e.Note != "" {
+				fmt.Fprintf(out, "Note: %s\n", e.Note)
+			}
+			return nil
+		},
+	}
+)
+
+func printEntries(w io.Writer, entries []corrections.Entry) {
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s -> %s\n", e.Typo, e.Correction)
+	}
+}
+
+func init() {
+	correctionsCmd.AddCommand(correctionsAddCmd, correctionsRemoveCmd, correctionsListCmd,
+		correctionsSearchCmd, correctionsShowCmd, correctionsLintCmd, correctionsImportCmd,
+		correctionsExportCmd, correctionsLanguagesCmd)
+	correctionsLintCmd.Flags().StringVarP(&wordListFlag, "wordlist", "w", "",
+		"dictionary word list to report typos that are real words (default from config)")
+	correctionsImportCmd.Flags().StringVarP(&importFormatFlag, "format", "f", "",
+		"format to import ("+strings.Join(convert.ImportFormats(), ", ")+")")
+	correctionsImportCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "file to write the corrections to")
+	correctionsExportCmd.Flags().StringVarP(&exportFormatFlag, "format", "f", "",
+		"format to export ("+strings.Join(convert.ExportFormats(), ", ")+")")
+	correctionsExportCmd.MarkFlagRequired("format")
+	correctionsExportCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "file to write the converted list to")
+	rootCmd.AddCommand(correctionsCmd)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inPlaceFlag bool
+	backupFlag  string
+	fixCmd      = &cobra.Command{
+		Use:   "fix [FILE...]",
+		Short: "Correct typos in text.",
+		Long: `Correct typos in the given files, or in standard input if no files are given, using the corrections list.
+The corrected text is written to standard output, unless --in-place is used to rewrite the files.
+When rewriting files, the original is kept with the --backup suffix appended to its name.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := corrections.NewCorrections(languages()...)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				if inPlaceFlag {
+					return errors.New("--in-place requires at least one file")
+				}
+				text, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				fixed, _ := c.Fix(string(text))
+				_, err = io.WriteString(cmd.OutOrStdout(), fixed)
+				return err
+			}
+			for _, file := range args {
+				if err := fixFile(c, file, cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+)
+
+// fixFile corrects the typos in a file, either writing the corrected text to
+// out or rewriting the file in place.
+func fixFile(c *corrections.Corrections, file string, out io.Writer) error {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	fixed, matches := c.Fix(string(text))
+	if !inPlaceFlag {
+		_, err := io.WriteString(out, fixed)
+		return err
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if backupFlag != "" {
+		if err := os.WriteFile(file+backupFlag, text, info.Mode().Perm()); err != nil {
+			return errors.Join(errors.New("could not back up "+file), err)
+		}
+	}
+	if err := os.WriteFile(file, []byte(fixed), info.Mode().Perm()); err != nil {
+		return err
+	}
+	log.Info().Str("file", file).Int("corrections", len(matches)).Msg("Corrected file.")
+	return nil
+}
+
+func init() {
+	fixCmd.Flags().BoolVarP(&inPlaceFlag, "in-place", "i", false, "rewrite the files instead of writing to standard output")
+	fixCmd.Flags().StringVar(&backupFlag, "backup", ".orig", "suffix for backups of rewritten files, or empty for no backup")
+	rootCmd.AddCommand(fixCmd)
+}
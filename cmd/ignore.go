@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ignoreCmd = &cobra.Command{
+		Use:   "ignore",
+		Short: "Manage the list of words that will not be corrected.",
+		Long: `Words whose corrections are repeatedly reverted are added to an ignore list and are no longer corrected.
+These commands show and clear that list.`,
+	}
+	ignoreListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the ignored words.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			words, err := corrections.Ignored()
+			if err != nil {
+				return err
+			}
+			for _, word := range words {
+				fmt.Fprintln(cmd.OutOrStdout(), word)
+			}
+			return nil
+		},
+	}
+	ignoreClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Clear the ignored words.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return corrections.ClearIgnored()
+		},
+	}
+)
+
+func init() {
+	ignoreCmd.AddCommand(ignoreListCmd, ignoreClearCmd)
+	rootCmd.AddCommand(ignoreCmd)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/joshuar/autocorrector/internal/lsp"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server for the corrections list.",
+	Long: `Run a Language Server Protocol server over standard input and output.
+Words in the corrections list are reported as diagnostics, with code actions to replace them with their correction.
+Configure an editor to run "autocorrector lsp" as a language server for the file types to check.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		c, err := corrections.NewCorrections(languages()...)
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(ctx); err != nil {
+			log.Warn().Err(err).Msg("Could not watch corrections files, changes will require a restart.")
+		}
+		return lsp.NewServer(c, os.Stdin, os.Stdout).Run()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
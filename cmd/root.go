@@ -6,29 +6,34 @@
 package cmd
 
 import (
+	"errors"
 	_ "net/http/pprof"
 	"os"
 
 	"github.com/joshuar/autocorrector/internal/app"
-	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 var (
-	userFlag    string
-	debugFlag   bool
-	profileFlag bool
-	rootCmd     = &cobra.Command{
+	userFlag     string
+	debugFlag    bool
+	profileFlag  bool
+	languageFlag []string
+	rootCmd      = &cobra.Command{
 		Use:   "autocorrector",
 		Short: "Autocorrect typos and spelling mistakes.",
 		Long:  `Autocorrector is a tool similar to the word replacement functionality in Autokey or AutoHotKey.`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// the arguments and flags have been checked by now, so any
+			// later error is not a usage error
+			cmd.SilenceUsage = true
 			setLogging()
 			setDebugging()
 			setProfiling()
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			app := app.New()
+			app.Languages = languageFlag
 			app.Run()
 		},
 	}
@@ -37,14 +42,30 @@ var (
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// cobra prints the error
 	if err := rootCmd.Execute(); err != nil {
-		log.Panic().Err(err).Msg("Could not start.")
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitError is an error that exits with a status other than 1.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
 // init defines flags and configuration settings
 func init() {
-	rootCmd.Flags().BoolVarP(&debugFlag, "debug", "d", false, "debug output")
+	rootCmd.PersistentFlags().BoolVarP(&debugFlag, "debug", "d", false, "debug output")
+	rootCmd.PersistentFlags().StringSliceVarP(&languageFlag, "language", "l", nil,
+		"languages whose corrections are used (default from config, or all languages)")
 	rootCmd.Flags().BoolVarP(&profileFlag, "profile", "", false, "enable profiling")
 }
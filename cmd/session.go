@@ -0,0 +1,154 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/joshuar/autocorrector/internal/focus"
+	"github.com/joshuar/autocorrector/internal/keytracker"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	redactFlag bool
+	recordCmd  = &cobra.Command{
+		Use:   "record FILE",
+		Short: "Record key presses to a file, for replaying later.",
+		Long: `Record the key presses from all keyboards to a file until interrupted with Ctrl+C.
+The recording can be replayed with the replay command to reproduce the corrections that were made.
+Use --redact to replace letters and digits, so that the recording does not contain what was typed.
+Nothing is recorded while a password prompt or other window for secrets has the focus.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			ctx, cancelFunc := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancelFunc()
+			cfg, err := config.Load(config.Path)
+			if err != nil {
+				return err
+			}
+			log.Info().Str("file", args[0]).Msg("Recording key presses, press Ctrl+C to stop.")
+			if err := keytracker.RecordSession(ctx, keytracker.NewKeyboardInput(ctx), f, redactFlag, focus.NewX11(), cfg.SecureInput); err != nil {
+				return err
+			}
+			return f.Close()
+		},
+	}
+	replayCmd = &cobra.Command{
+		Use:   "replay FILE",
+		Short: "Replay recorded key presses and show the corrections that would be made.",
+		Long: `Replay a recording made with the record command through the current corrections and configuration.
+Nothing is typed: each correction that would be made is printed instead.
+Words that are learnt or ignored during the replay are not saved.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			ctx, cancelFunc := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancelFunc()
+			cfg, err := config.Load(config.Path)
+			if err != nil {
+				return err
+			}
+			if len(languageFlag) > 0 {
+				cfg.Languages.Active = languageFlag
+			}
+			input, err := keytracker.NewReplayInput(ctx, f)
+			if err != nil {
+				return err
+			}
+			agent := newReplayAgent()
+			kt, err := keytracker.NewDryRunKeyTracker(ctx, input, &keytracker.RecordingOutput{}, nil, cfg, agent, replayStats{})
+			if err != nil {
+				return err
+			}
+			agent.printCorrections(cmd.OutOrStdout(), kt)
+			return nil
+		},
+	}
+)
+
+// replayAgent receives the corrections made while replaying a recording.
+type replayAgent struct {
+	notificationsCh chan *keytracker.Correction
+	suggestionsCh   chan *corrections.Suggestion
+	toggleCh        chan bool
+	paused          bool
+}
+
+func newReplayAgent() *replayAgent {
+	return &replayAgent{
+		notificationsCh: make(chan *keytracker.Correction),
+		suggestionsCh:   make(chan *corrections.Suggestion),
+		toggleCh:        make(chan bool),
+	}
+}
+
+func (a *replayAgent) NotificationCh() chan *keytracker.Correction {
+	return a.notificationsCh
+}
+
+func (a *replayAgent) SuggestionCh() chan *corrections.Suggestion {
+	return a.suggestionsCh
+}
+
+func (a *replayAgent) Toggle() {
+	a.paused = !a.paused
+	a.toggleCh <- a.paused
+}
+
+// printCorrections prints each correction as it is made, and passes on the
+// toggle shortcut, until the keytracker is done.
+func (a *replayAgent) printCorrections(w io.Writer, kt *keytracker.KeyTracker) {
+	for {
+		select {
+		case c := <-a.notificationsCh:
+			fmt.Fprintf(w, "%s -> %s\n", c.Word, c.Correction)
+		case paused := <-a.toggleCh:
+			fmt.Fprintf(w, "(corrections paused: %t)\n", paused)
+			select {
+			case kt.ToggleCh <- paused:
+			case <-kt.Done:
+				return
+			}
+		case <-kt.Done:
+			return
+		}
+	}
+}
+
+// replayStats discards the statistics of a replay, so that they are not
+// counted along with those of real typing.
+type replayStats struct{}
+
+func (replayStats) IncKeyCounter()            {}
+func (replayStats) IncBackspaceCounter()      {}
+func (replayStats) IncCheckedCounter()        {}
+func (replayStats) IncCorrectedCounter()      {}
+func (replayStats) IncSpellCheckedCounter()   {}
+func (replayStats) IncSpellCorrectedCounter() {}
+func (replayStats) IncSpellRevertedCounter()  {}
+
+func init() {
+	recordCmd.Flags().BoolVarP(&redactFlag, "redact", "r", false, "replace letters and digits in the recording")
+	rootCmd.AddCommand(recordCmd, replayCmd)
+}
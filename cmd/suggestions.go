@@ -0,0 +1,56 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestionsCmd = &cobra.Command{
+		Use:   "suggestions",
+		Short: "Manage corrections learnt from retyped words.",
+		Long: `When a word is repeatedly deleted and retyped with a small change, autocorrector suggests it as a new correction.
+These commands show suggested corrections and add them to, or dismiss them from, your corrections file.`,
+	}
+	suggestionsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List suggested corrections.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			suggestions, err := corrections.Suggestions()
+			if err != nil {
+				return err
+			}
+			for _, s := range suggestions {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s -> %s\n", s.Typo, s.Correction)
+			}
+			return nil
+		},
+	}
+	suggestionsAcceptCmd = &cobra.Command{
+		Use:   "accept [typo...]",
+		Short: "Add suggested corrections to your corrections file (all if none given).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return corrections.AcceptSuggestions(args...)
+		},
+	}
+	suggestionsDismissCmd = &cobra.Command{
+		Use:   "dismiss [typo...]",
+		Short: "Dismiss suggested corrections (all if none given).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return corrections.DismissSuggestions(args...)
+		},
+	}
+)
+
+func init() {
+	suggestionsCmd.AddCommand(suggestionsListCmd, suggestionsAcceptCmd, suggestionsDismissCmd)
+	rootCmd.AddCommand(suggestionsCmd)
+}
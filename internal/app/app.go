@@ -11,11 +11,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"sync"
 	"syscall"
 
 	"fyne.io/fyne/v2"
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/corrections"
 	"github.com/joshuar/autocorrector/internal/db"
 	"github.com/joshuar/autocorrector/internal/keytracker"
 	"github.com/rs/zerolog/log"
@@ -27,7 +28,7 @@ var Version string
 
 var debugAppID = ""
 
-var configPath = filepath.Join(os.Getenv("HOME"), ".config", "autocorrector")
+var configPath = config.Path
 
 const (
 	Name      = "autocorrector"
@@ -40,20 +41,45 @@ type App struct {
 	Name, Version     string
 	showNotifications bool
 	notificationsCh   chan *keytracker.Correction
+	suggestionsCh     chan *corrections.Suggestion
 	paused            bool
-	toggleCh          chan bool
-	Done              chan struct{}
+	// pausedMu guards paused, as corrections are toggled both from the tray
+	// and by the keyboard shortcut.
+	pausedMu sync.Mutex
+	toggleCh chan bool
+	// Languages, if set, are the languages whose corrections are used,
+	// overriding the config file.
+	Languages   []string
+	languages   []string
+	languagesCh chan []string
+	Done        chan struct{}
 }
 
 func (a *App) NotificationCh() chan *keytracker.Correction {
 	return a.notificationsCh
 }
 
+func (a *App) SuggestionCh() chan *corrections.Suggestion {
+	return a.suggestionsCh
+}
+
+// Toggle turns corrections off, or back on. The change is sent while holding
+// the lock, so that the keytracker sees the changes from concurrent toggles in
+// the same order as they were made.
 func (a *App) Toggle() {
+	a.pausedMu.Lock()
+	defer a.pausedMu.Unlock()
 	a.paused = !a.paused
 	a.toggleCh <- a.paused
 }
 
+// SetLanguages changes the languages whose corrections are used. If none are
+// given, the corrections for every language are used.
+func (a *App) SetLanguages(languages ...string) {
+	a.languages = languages
+	a.languagesCh <- languages
+}
+
 func New() *App {
 	return &App{
 		app:               newUI(),
@@ -61,7 +87,9 @@ func New() *App {
 		Version:           Version,
 		showNotifications: false,
 		notificationsCh:   make(chan *keytracker.Correction),
+		suggestionsCh:     make(chan *corrections.Suggestion),
 		toggleCh:          make(chan bool),
+		languagesCh:       make(chan []string),
 		Done:              make(chan struct{}),
 	}
 }
@@ -78,7 +106,17 @@ func (a *App) Run() {
 		log.Fatal().Err(err).Msg("Failed to start stats tracking.")
 	}
 
-	keyTracker, err := keytracker.NewKeyTracker(ctx, a, stats)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Could not load config.")
+	}
+	if len(a.Languages) > 0 {
+		cfg.Languages.Active = a.Languages
+		cfg.Languages.FollowLayout = false
+	}
+	a.languages = cfg.Languages.Active
+
+	keyTracker, err := keytracker.NewKeyTracker(ctx, cfg, a, stats)
 	defer close(keyTracker.ToggleCh)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Could not start keytracker.")
@@ -99,8 +137,16 @@ func (a *App) Run() {
 						Content: fmt.Sprintf("Corrected %s with %s", n.Word, n.Correction),
 					})
 				}
+			case s := <-a.suggestionsCh:
+				a.app.SendNotification(&fyne.Notification{
+					Title: "Suggested Correction",
+					Content: fmt.Sprintf("You often retype %s as %s. Add it as a correction from Suggested Corrections in the tray menu.",
+						s.Typo, s.Correction),
+				})
 			case v := <-a.toggleCh:
 				keyTracker.ToggleCh <- v
+			case languages := <-a.languagesCh:
+				keyTracker.LanguageCh <- languages
 			}
 		}
 	}()
@@ -9,6 +9,7 @@ import (
 	_ "embed"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -17,6 +18,7 @@ import (
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
+	"github.com/joshuar/autocorrector/internal/corrections"
 	"github.com/joshuar/autocorrector/internal/db"
 	"github.com/rs/zerolog/log"
 )
@@ -83,10 +85,27 @@ func (a *App) setupSystemTray(stats *db.Stats) {
 			NewMenuItem("Show Stats", func() {
 				a.statsWindow(stats)
 			})
-		menu := fyne.NewMenu(a.Name,
+		menuItemIgnored := fyne.
+			NewMenuItem("Ignored Words", a.ignoredWindow)
+		menuItemSuggestions := fyne.
+			NewMenuItem("Suggested Corrections", a.suggestionsWindow)
+		menuItemAddCorrection := fyne.
+			NewMenuItem("Add Correction", a.addCorrectionWindow)
+		items := []*fyne.MenuItem{
 			menuItemAbout,
 			menuItemSettings,
 			menuItemStats,
+			menuItemIgnored,
+			menuItemSuggestions,
+			menuItemAddCorrection,
+		}
+		menu := fyne.NewMenu(a.Name)
+		if languages := corrections.AvailableLanguages(); len(languages) > 0 {
+			menuItemLanguages := fyne.NewMenuItem("Languages", nil)
+			menuItemLanguages.ChildMenu = a.languagesMenu(menu, languages)
+			items = append(items, menuItemLanguages)
+		}
+		menu.Items = append(items,
 			menuItemToggleNotifications,
 			menuItemToggleKeyTracker,
 			menuItemIssue,
@@ -97,6 +116,51 @@ func (a *App) setupSystemTray(stats *db.Stats) {
 	a.tray.Hide()
 }
 
+// languagesMenu creates a menu for choosing the languages whose corrections
+// are used, with an item to use every language and an item to turn each
+// language on or off. The tray menu is refreshed when the choice changes.
+func (a *App) languagesMenu(tray *fyne.Menu, languages []string) *fyne.Menu {
+	menu := fyne.NewMenu("Languages")
+	update := func() {
+		active := make(map[string]bool)
+		for _, lang := range a.languages {
+			active[lang] = true
+		}
+		menu.Items[0].Checked = len(a.languages) == 0
+		for i, lang := range languages {
+			menu.Items[i+1].Checked = active[lang]
+		}
+	}
+	menu.Items = append(menu.Items, fyne.NewMenuItem("All Languages", func() {
+		a.SetLanguages()
+		update()
+		tray.Refresh()
+	}))
+	for _, lang := range languages {
+		lang := lang
+		menu.Items = append(menu.Items, fyne.NewMenuItem(lang, func() {
+			var selected []string
+			removed := false
+			for _, l := range a.languages {
+				if l == lang {
+					removed = true
+					continue
+				}
+				selected = append(selected, l)
+			}
+			if !removed {
+				selected = append(selected, lang)
+			}
+			log.Debug().Strs("languages", selected).Msg("Changing languages.")
+			a.SetLanguages(selected...)
+			update()
+			tray.Refresh()
+		}))
+	}
+	update()
+	return menu
+}
+
 func (a *App) settingsWindow() {
 	w := a.app.NewWindow("Fyne Settings")
 	w.SetContent(settings.NewSettings().LoadAppearanceScreen(w))
@@ -117,8 +181,110 @@ func (a *App) statsWindow(stats *db.Stats) {
 		container.New(layout.NewGridLayout(3),
 			widget.NewLabel(fmt.Sprintf("Keys Pressed: %d", stats.GetKeysPressed())),
 			widget.NewLabel(fmt.Sprintf("Backspace Pressed: %d", stats.GetBackspacePressed())),
-			widget.NewLabel(fmt.Sprintf("Correction Rate: %.2f%%", stats.GetEfficiency()))))
+			widget.NewLabel(fmt.Sprintf("Correction Rate: %.2f%%", stats.GetEfficiency()))),
+		container.New(layout.NewGridLayout(3),
+			widget.NewLabel(fmt.Sprintf("Spell Checked: %d", stats.GetSpellCheckedTotal())),
+			widget.NewLabel(fmt.Sprintf("Spell Corrected: %d (%d reverted)",
+				stats.GetSpellCorrectedTotal(), stats.GetSpellRevertedTotal())),
+			widget.NewLabel(fmt.Sprintf("Spell Accuracy: %.2f%%", stats.GetSpellAccuracy()))))
 	w.SetContent(content)
 	w.Resize(fyne.NewSize(164, 144))
 	w.Show()
 }
+
+func (a *App) ignoredWindow() {
+	w := a.app.NewWindow("Ignored Words")
+	words, err := corrections.Ignored()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not open ignore list.")
+	}
+	list := widget.NewLabel(strings.Join(words, "\n"))
+	if len(words) == 0 {
+		list.SetText("No words are being ignored.")
+	}
+	content := container.New(layout.NewVBoxLayout(),
+		list,
+		container.New(layout.NewHBoxLayout(),
+			layout.NewSpacer(),
+			widget.NewButton("Clear", func() {
+				if err := corrections.ClearIgnored(); err != nil {
+					log.Warn().Err(err).Msg("Could not clear ignore list.")
+					return
+				}
+				list.SetText("No words are being ignored.")
+			}),
+			widget.NewButton("Ok", func() {
+				w.Close()
+			})))
+	w.SetContent(content)
+	w.Show()
+}
+
+func (a *App) suggestionsWindow() {
+	w := a.app.NewWindow("Suggested Corrections")
+	suggestions, err := corrections.Suggestions()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not open suggested corrections.")
+	}
+	lines := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		lines = append(lines, fmt.Sprintf("%s → %s", s.Typo, s.Correction))
+	}
+	list := widget.NewLabel(strings.Join(lines, "\n"))
+	if len(suggestions) == 0 {
+		list.SetText("There are no suggested corrections.")
+	}
+	content := container.New(layout.NewVBoxLayout(),
+		list,
+		container.New(layout.NewHBoxLayout(),
+			layout.NewSpacer(),
+			widget.NewButton("Accept All", func() {
+				if err := corrections.AcceptSuggestions(); err != nil {
+					log.Warn().Err(err).Msg("Could not accept suggested corrections.")
+					return
+				}
+				list.SetText("There are no suggested corrections.")
+			}),
+			widget.NewButton("Dismiss All", func() {
+				if err := corrections.DismissSuggestions(); err != nil {
+					log.Warn().Err(err).Msg("Could not dismiss suggested corrections.")
+					return
+				}
+				list.SetText("There are no suggested corrections.")
+			}),
+			widget.NewButton("Ok", func() {
+				w.Close()
+			})))
+	w.SetContent(content)
+	w.Show()
+}
+
+func (a *App) addCorrectionWindow() {
+	w := a.app.NewWindow("Add Correction")
+	typo := widget.NewEntry()
+	typo.SetPlaceHolder("Typo")
+	correction := widget.NewEntry()
+	correction.SetPlaceHolder("Correction")
+	status := widget.NewLabel("")
+	content := container.New(layout.NewVBoxLayout(),
+		typo,
+		correction,
+		status,
+		container.New(layout.NewHBoxLayout(),
+			layout.NewSpacer(),
+			widget.NewButton("Add", func() {
+				if err := corrections.AddCorrection(typo.Text, correction.Text); err != nil {
+					log.Warn().Err(err).Msg("Could not add correction.")
+					status.SetText(err.Error())
+					return
+				}
+				status.SetText(fmt.Sprintf("Added %s → %s", typo.Text, correction.Text))
+				typo.SetText("")
+				correction.SetText("")
+			}),
+			widget.NewButton("Ok", func() {
+				w.Close()
+			})))
+	w.SetContent(content)
+	w.Show()
+}
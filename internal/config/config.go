@@ -0,0 +1,175 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const configFilename = "config.toml"
+
+// Path is the directory holding the config file, along with the user's
+// corrections and statistics.
+var Path = filepath.Join(os.Getenv("HOME"), ".config", "autocorrector")
+
+// Config holds the optional settings for autocorrector, read from config.toml
+// in the config directory. Any setting not in the file keeps its default.
+type Config struct {
+	SpellCheck SpellCheck `toml:"spellcheck"`
+	Languages  Languages  `toml:"languages"`
+	// Profiles change how autocorrector behaves in particular applications,
+	// by name.
+	Profiles    map[string]Profile `toml:"profiles"`
+	SecureInput SecureInput        `toml:"secure_input"`
+	Shortcuts   Shortcuts          `toml:"shortcuts"`
+}
+
+// SpellCheck controls correcting words that are not in the corrections list
+// using a dictionary word list.
+type SpellCheck struct {
+	// Enabled turns on spell checking. It is off by default.
+	Enabled bool `toml:"enabled"`
+	// WordList is the path to the dictionary, either a plain list of words
+	// (one per line) or a hunspell .dic file.
+	WordList string `toml:"wordlist"`
+	// Confidence is the minimum confidence (0-1) needed to make a correction.
+	Confidence float64 `toml:"confidence"`
+	// MaxDistance is the largest number of edits between a word and a
+	// suggestion.
+	MaxDistance int `toml:"max_distance"`
+}
+
+// Languages chooses which language-specific corrections files are used.
+type Languages struct {
+	// Active are the languages whose corrections are used. If empty, the
+	// corrections for every language are used.
+	Active []string `toml:"active"`
+	// FollowLayout switches the active language to match the current
+	// keyboard layout whenever it changes.
+	FollowLayout bool `toml:"follow_layout"`
+	// Layouts maps keyboard layout names to languages, for layouts that are
+	// not named after their language.
+	Layouts map[string]string `toml:"layouts"`
+}
+
+// Profile changes how autocorrector behaves while one of its applications has
+// the keyboard focus.
+type Profile struct {
+	// Apps are the window classes (or Wayland app IDs) of the applications
+	// the profile applies to.
+	Apps []string `toml:"apps"`
+	// Enabled turns correcting on or off in the applications. It is on
+	// unless set.
+	Enabled *bool `toml:"enabled"`
+	// Languages, if set, are the languages whose corrections are used in the
+	// applications instead of the active languages.
+	Languages []string `toml:"languages"`
+}
+
+// CorrectionsEnabled reports whether corrections are made in the profile's
+// applications.
+func (p *Profile) CorrectionsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// Profile returns the profile for an application, where match reports whether
+// an application name from a profile is the application. If more than one
+// profile applies, the first by name is used.
+func (c *Config) Profile(match func(app string) bool) (string, *Profile, bool) {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := c.Profiles[name]
+		for _, app := range p.Apps {
+			if match(app) {
+				return name, &p, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// SecureInput lists the windows where passwords and other secrets are typed.
+// Autocorrector stops tracking key presses while one of them has the keyboard
+// focus.
+type SecureInput struct {
+	// Apps are the window classes (or Wayland app IDs) of applications that
+	// are always secure, such as password managers.
+	Apps []string `toml:"apps"`
+	// Titles are regular expressions matched against the title of the
+	// focused window, such as a terminal running sudo.
+	Titles []string `toml:"titles"`
+}
+
+// Shortcuts are key chords that control autocorrector from the keyboard,
+// written as modifiers and a key joined by +, such as Ctrl+Alt+Shift+A. An
+// empty chord turns the shortcut off.
+type Shortcuts struct {
+	// Toggle turns corrections on and off.
+	Toggle string `toml:"toggle"`
+	// Correct corrects the word being typed straight away, without waiting
+	// for it to be finished.
+	Correct string `toml:"correct"`
+	// Skip leaves the word being typed uncorrected.
+	Skip string `toml:"skip"`
+}
+
+func defaults() *Config {
+	return &Config{
+		SpellCheck: SpellCheck{
+			Enabled:     false,
+			WordList:    "/usr/share/dict/words",
+			Confidence:  0.8,
+			MaxDistance: 2,
+		},
+		Shortcuts: Shortcuts{
+			Toggle: "Ctrl+Alt+Shift+A",
+		},
+		SecureInput: SecureInput{
+			Apps: []string{
+				"1Password", "Bitwarden", "KeePass2", "KeePassXC",
+				"Pinentry", "Pinentry-gtk-2", "Pinentry-qt", "Gcr-prompter",
+				"Polkit-gnome-authentication-agent-1", "polkit-kde-authentication-agent-1",
+				"Ssh-askpass", "Lxpolkit",
+			},
+			Titles: []string{
+				`(?i)\b(sudo|doas|su|passwd|ssh-add|pinentry)\b`,
+				`(?i)\bpass(word|phrase)\b`,
+				`(?i)authenticat(e|ion) required`,
+			},
+		},
+	}
+}
+
+// Load reads the config file from the given directory. If there is no config
+// file, the defaults are returned.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+	file := filepath.Join(path, configFilename)
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Debug().Str("file", file).Msg("No config file, using defaults.")
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := toml.Unmarshal(b, cfg); err != nil {
+		return nil, errors.Join(errors.New("could not parse config file "+file), err)
+	}
+	log.Info().Str("file", file).Msg("Opened config file.")
+	return cfg, nil
+}
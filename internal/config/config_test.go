@@ -0,0 +1,70 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, configFilename), []byte(`
+[profiles.terminals]
+apps = ['kitty', 'org.gnome.Terminal']
+enabled = false
+
+[profiles.editors]
+apps = ['code', 'kitty']
+languages = ['en']
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		app         string
+		wantProfile string
+		wantEnabled bool
+	}{
+		{name: "match", app: "org.gnome.Terminal", wantProfile: "terminals", wantEnabled: false},
+		{name: "case", app: "Code", wantProfile: "editors", wantEnabled: true},
+		{name: "first by name", app: "kitty", wantProfile: "editors", wantEnabled: true},
+		{name: "no profile", app: "firefox", wantProfile: "", wantEnabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, profile, ok := cfg.Profile(func(app string) bool {
+				return strings.EqualFold(app, tt.app)
+			})
+			if name != tt.wantProfile || ok != (tt.wantProfile != "") {
+				t.Fatalf("profile is %q (%t), want %q", name, ok, tt.wantProfile)
+			}
+			if ok && profile.CorrectionsEnabled() != tt.wantEnabled {
+				t.Errorf("corrections enabled = %t, want %t", profile.CorrectionsEnabled(), tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cfg.Profile(func(string) bool { return true }); ok {
+		t.Error("default config has a profile")
+	}
+	if len(cfg.SecureInput.Apps) == 0 || len(cfg.SecureInput.Titles) == 0 {
+		t.Error("default config has no secure input windows")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package convert converts corrections to and from the formats used by other
+// autocorrect tools.
+package convert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// importer reads the corrections from a file or directory, by typo.
+type importer func(path string) (map[string]corrections.Entry, error)
+
+var importers = map[string]importer{
+	"autohotkey":   importAutoHotkey,
+	"autokey":      importAutokey,
+	"codespell":    importCodespell,
+	"documentlist": importDocumentList,
+	"espanso":      importEspanso,
+}
+
+// ImportFormats returns the names of the formats that can be imported.
+func ImportFormats() []string {
+	return formatNames(importers)
+}
+
+func formatNames[T any](formats map[string]T) []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectFormat guesses the format of a file to import from its name. A
+// directory is assumed to be an Autokey folder.
+func DetectFormat(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "autokey", nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ahk":
+		return "autohotkey", nil
+	case ".yml", ".yaml":
+		return "espanso", nil
+	case ".xml", ".dat":
+		return "documentlist", nil
+	case ".txt":
+		return "codespell", nil
+	default:
+		return "", fmt.Errorf("cannot tell the format of %s, choose one of %s",
+			path, strings.Join(ImportFormats(), ", "))
+	}
+}
+
+// Import reads the corrections from a file or directory in the given format,
+// sorted by typo. Entries that cannot be represented as a correction, such as
+// those that run scripts or have several possible corrections, are skipped.
+func Import(format, path string) ([]corrections.Entry, error) {
+	fn, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, choose one of %s", format, strings.Join(ImportFormats(), ", "))
+	}
+	imported, err := fn(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]corrections.Entry, 0, len(imported))
+	for _, e := range imported {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Typo < entries[j].Typo
+	})
+	return entries, nil
+}
+
+// MarshalTOML writes imported corrections as a corrections file. Exact
+// corrections are written in the exact section, and verbatim corrections as
+// entry tables with the verbatim case option.
+func MarshalTOML(entries []corrections.Entry) ([]byte, error) {
+	doc := make(map[string]any)
+	exact := make(map[string]string)
+	for _, e := range entries {
+		switch {
+		case e.Exact:
+			exact[e.Typo] = e.Correction
+		case e.Verbatim:
+			doc[e.Typo] = map[string]string{"replacement": e.Correction, "case": "verbatim"}
+		default:
+			doc[e.Typo] = e.Correction
+		}
+	}
+	if len(exact) > 0 {
+		doc["exact"] = exact
+	}
+	return toml.Marshal(doc)
+}
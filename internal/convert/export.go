@@ -0,0 +1,193 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// exporter writes corrections in another tool's format.
+type exporter func(w io.Writer, entries []corrections.Entry) error
+
+var exporters = map[string]exporter{
+	"autohotkey": exportAutoHotkey,
+	"codespell":  exportCodespell,
+	"espanso":    exportEspanso,
+	"json":       exportJSON,
+	"vim":        exportVim,
+}
+
+// ExportFormats returns the names of the formats that can be exported.
+func ExportFormats() []string {
+	return formatNames(exporters)
+}
+
+// Export writes the corrections to w in the given format. Corrections that
+// cannot be represented in the format, such as phrases in formats that only
+// support single words, are skipped.
+func Export(w io.Writer, format string, entries []corrections.Entry) error {
+	fn, ok := exporters[format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, choose one of %s", format, strings.Join(ExportFormats(), ", "))
+	}
+	return fn(w, entries)
+}
+
+func skip(e corrections.Entry, format, reason string) {
+	log.Debug().Str("typo", e.Typo).Str("format", format).Msgf("Skipping correction, %s.", reason)
+}
+
+// exportAutoHotkey writes each correction as an AutoHotkey hotstring. Exact
+// corrections are case-sensitive hotstrings.
+func exportAutoHotkey(w io.Writer, entries []corrections.Entry) error {
+	escape := strings.NewReplacer("`", "``", ";", "`;", "\n", "`n", "\t", "`t")
+	for _, e := range entries {
+		if strings.Contains(e.Typo, "::") || strings.HasPrefix(e.Typo, ":") {
+			skip(e, "autohotkey", "it contains a colon")
+			continue
+		}
+		options := "R"
+		if e.Exact {
+			options += "C"
+		}
+		if _, err := fmt.Fprintf(w, ":%s:%s::%s\n", options, escape.Replace(e.Typo), escape.Replace(e.Correction)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportCodespell writes a codespell dictionary. Codespell only checks single
+// words, and a comma in a correction would separate alternatives.
+func exportCodespell(w io.Writer, entries []corrections.Entry) error {
+	for _, e := range entries {
+		if strings.ContainsAny(e.Typo, " \t") || strings.ContainsAny(e.Correction, ",\n") {
+			skip(e, "codespell", "it is a phrase or contains a comma")
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s->%s\n", e.Typo, e.Correction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type espansoMatch struct {
+	Trigger       string `yaml:"trigger"`
+	Replace       string `yaml:"replace"`
+	Word          bool   `yaml:"word"`
+	PropagateCase bool   `yaml:"propagate_case,omitempty"`
+}
+
+// exportEspanso writes an Espanso match file. Matches only trigger on whole
+// words and, like autocorrector, corrections that are not exact or verbatim
+// follow the capitalisation of the typed word.
+func exportEspanso(w io.Writer, entries []corrections.Entry) error {
+	file := struct {
+		Matches []espansoMatch `yaml:"matches"`
+	}{}
+	for _, e := range entries {
+		file.Matches = append(file.Matches, espansoMatch{
+			Trigger:       e.Typo,
+			Replace:       e.Correction,
+			Word:          true,
+			PropagateCase: !e.Exact && !e.Verbatim && strings.ToLower(e.Typo) == e.Typo,
+		})
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type jsonCorrection struct {
+	Typo       string `json:"typo"`
+	Correction string `json:"correction"`
+	Exact      bool   `json:"exact,omitempty"`
+}
+
+// exportJSON writes the corrections as a JSON array of objects.
+func exportJSON(w io.Writer, entries []corrections.Entry) error {
+	list := make([]jsonCorrection, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, jsonCorrection{Typo: e.Typo, Correction: e.Correction, Exact: e.Exact})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(list)
+}
+
+// exportVim writes each correction as a Vim insert mode abbreviation. Vim
+// abbreviations are case-sensitive, so each way of capitalising a typo that
+// autocorrector would correct gets its own abbreviation. Vim abbreviations
+// cannot contain spaces, and Vim rejects those that end in a keyword
+// character but mix keyword and other characters before it, such as 1/2th.
+func exportVim(w io.Writer, entries []corrections.Entry) error {
+	escape := strings.NewReplacer("|", "<Bar>", "\\", "<Bslash>")
+	var invalid int
+	for _, e := range corrections.CaseVariants(entries) {
+		if strings.ContainsAny(e.Typo, " \t") || strings.Contains(e.Correction, "\n") {
+			skip(e, "vim", "it is a phrase or has several lines")
+			continue
+		}
+		if !isVimAbbreviation(e.Typo) {
+			skip(e, "vim", "it is not a valid abbreviation")
+			invalid++
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "iabbrev %s %s\n", escape.Replace(e.Typo), escape.Replace(e.Correction)); err != nil {
+			return err
+		}
+	}
+	if invalid > 0 {
+		log.Warn().Int("skipped", invalid).Msg("Skipped typos that Vim does not allow as abbreviations.")
+	}
+	return nil
+}
+
+// isVimAbbreviation reports whether Vim accepts the typo as an abbreviation:
+// either all keyword characters, a keyword character after only non-keyword
+// characters, or ending in a non-keyword character.
+func isVimAbbreviation(typo string) bool {
+	runes := []rune(typo)
+	if len(runes) == 0 {
+		return false
+	}
+	if !isVimKeyword(runes[len(runes)-1]) {
+		return true
+	}
+	var keywords int
+	for _, r := range runes[:len(runes)-1] {
+		if isVimKeyword(r) {
+			keywords++
+		}
+	}
+	return keywords == 0 || keywords == len(runes)-1
+}
+
+// isVimKeyword reports whether Vim treats the character as part of a keyword
+// with the default iskeyword setting.
+func isVimKeyword(r rune) bool {
+	switch {
+	case r < 0x80:
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	case r < 0x100:
+		return r >= 0xc0
+	default:
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+)
+
+func TestExportVim(t *testing.T) {
+	entries := []corrections.Entry{
+		{Typo: "1/2th", Correction: "half"},
+		{Typo: "Adn", Correction: "And", Exact: true},
+		{Typo: "a lot", Correction: "alot"},
+		{Typo: "iso", Correction: "ISO"},
+		{Typo: "teh", Correction: "the"},
+		{Typo: "Teh", Correction: "Them"},
+		{Typo: "wierd", Correction: "weird", Verbatim: true},
+		{Typo: "x|y", Correction: "z"},
+	}
+	var out strings.Builder
+	if err := exportVim(&out, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `iabbrev Adn And
+iabbrev iso ISO
+iabbrev Iso ISO
+iabbrev ISO ISO
+iabbrev teh the
+iabbrev TEH THE
+iabbrev Teh Them
+iabbrev wierd weird
+iabbrev Wierd weird
+iabbrev WIERD weird
+`
+	if got := out.String(); got != want {
+		t.Errorf("exported:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIsVimAbbreviation(t *testing.T) {
+	for typo, want := range map[string]bool{
+		"teh":   true,
+		"#i":    true,
+		"foo#":  true,
+		"a.b.":  true,
+		"1/2th": false,
+		"a#b":   false,
+		"été":   true,
+		"x|y":   false,
+		"":      false,
+	} {
+		if got := isVimAbbreviation(typo); got != want {
+			t.Errorf("isVimAbbreviation(%q) = %t, want %t", typo, got, want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package convert
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// documentListFilename is the autocorrect list inside a LibreOffice acor_*.dat
+// file.
+const documentListFilename = "DocumentList.xml"
+
+var (
+	// hotstringRe matches an AutoHotkey hotstring: ":options:typo::replacement".
+	hotstringRe = regexp.MustCompile(`^:([^:]*):(.+?)::(.*)$`)
+	// ahkCommentRe matches a comment at the end of an AutoHotkey line.
+	ahkCommentRe = regexp.MustCompile(`\s+;.*$`)
+)
+
+// importAutoHotkey reads the hotstrings from an AutoHotkey script. Hotstrings
+// that run code or send special keys are skipped. Case-sensitive hotstrings
+// (the C option) are exact, and those that do not conform to the typed case
+// (C1) are verbatim.
+func importAutoHotkey(path string) (map[string]corrections.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	imported := make(map[string]corrections.Entry)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := hotstringRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		options, typo := strings.ToUpper(m[1]), m[2]
+		replacement := ahkCommentRe.ReplaceAllString(m[3], "")
+		raw := strings.ContainsAny(options, "RT")
+		switch {
+		case strings.Contains(options, "X"), replacement == "":
+			log.Debug().Str("hotstring", typo).Msg("Skipping hotstring that runs code.")
+			continue
+		case !raw && strings.ContainsAny(replacement, "{}^!+#"):
+			log.Debug().Str("hotstring", typo).Msg("Skipping hotstring that sends keys.")
+			continue
+		}
+		replacement = strings.NewReplacer("`;", ";", "`n", "\n", "`t", "\t", "``", "`").Replace(replacement)
+		e := corrections.Entry{Typo: typo, Correction: replacement}
+		switch ahkCaseOption(options) {
+		case "C":
+			e.Exact = true
+		case "C1":
+			e.Verbatim = true
+		}
+		imported[typo] = e
+	}
+	return imported, scanner.Err()
+}
+
+// ahkCaseOption returns the last case option of a hotstring: C, C0 or C1, or
+// an empty string if there is none. Options are single letters, some followed
+// by a number.
+func ahkCaseOption(options string) string {
+	var option string
+	for i := 0; i < len(options); i++ {
+		if options[i] != 'C' {
+			continue
+		}
+		option = "C"
+		if i+1 < len(options) && (options[i+1] == '0' || options[i+1] == '1') {
+			option += options[i+1 : i+2]
+		}
+	}
+	return option
+}
+
+// autokeyPhrase is the part of the metadata of an Autokey phrase needed to
+// import it. Older versions of Autokey allow a single abbreviation.
+type autokeyPhrase struct {
+	Abbreviation struct {
+		Abbreviations []string `json:"abbreviations"`
+		Abbreviation  string   `json:"abbreviation"`
+	} `json:"abbreviation"`
+}
+
+// importAutokey reads the phrases in an Autokey folder, and any folders in it,
+// that are triggered by an abbreviation. Each phrase is a text file with its
+// settings in a hidden JSON file of the same name.
+func importAutokey(path string) (map[string]corrections.Entry, error) {
+	imported := make(map[string]corrections.Entry)
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(file) != ".txt" {
+			return err
+		}
+		name := strings.TrimSuffix(d.Name(), ".txt")
+		b, err := os.ReadFile(filepath.Join(filepath.Dir(file), "."+name+".json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var phrase autokeyPhrase
+		if err := json.Unmarshal(b, &phrase); err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("Skipping phrase with invalid settings.")
+			return nil
+		}
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		abbreviations := phrase.Abbreviation.Abbreviations
+		if phrase.Abbreviation.Abbreviation != "" {
+			abbreviations = append(abbreviations, phrase.Abbreviation.Abbreviation)
+		}
+		for _, abbreviation := range abbreviations {
+			imported[abbreviation] = corrections.Entry{Typo: abbreviation, Correction: string(text)}
+		}
+		return nil
+	})
+	return imported, err
+}
+
+// importCodespell reads a codespell dictionary, with lines of the form
+// "typo->correction". Typos with several possible corrections, or that have
+// been disabled with a reason, are skipped.
+func importCodespell(path string) (map[string]corrections.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	imported := make(map[string]corrections.Entry)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		typo, fixes, ok := strings.Cut(line, "->")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var candidates []string
+		for _, fix := range strings.Split(fixes, ",") {
+			if fix = strings.TrimSpace(fix); fix != "" {
+				candidates = append(candidates, fix)
+			}
+		}
+		if len(candidates) != 1 {
+			continue
+		}
+		typo = strings.TrimSpace(typo)
+		imported[typo] = corrections.Entry{Typo: typo, Correction: candidates[0]}
+	}
+	return imported, scanner.Err()
+}
+
+// documentList is the autocorrect list format used by LibreOffice and
+// Microsoft Office.
+type documentList struct {
+	Blocks []struct {
+		Abbreviation string `xml:"abbreviated-name,attr"`
+		Name         string `xml:"name,attr"`
+	} `xml:"block"`
+}
+
+// importDocumentList reads a DocumentList.xml autocorrect list, or a
+// LibreOffice acor_*.dat file containing one.
+func importDocumentList(path string) (map[string]corrections.Entry, error) {
+	var r io.ReadCloser
+	if zr, err := zip.OpenReader(path); err == nil {
+		defer zr.Close()
+		if r, err = zr.Open(documentListFilename); err != nil {
+			return nil, errors.Join(errors.New("could not find "+documentListFilename+" in "+path), err)
+		}
+	} else if r, err = os.Open(path); err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var list documentList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	imported := make(map[string]corrections.Entry)
+	for _, block := range list.Blocks {
+		if block.Abbreviation != "" && block.Name != "" {
+			imported[block.Abbreviation] = corrections.Entry{Typo: block.Abbreviation, Correction: block.Name}
+		}
+	}
+	return imported, nil
+}
+
+// espansoFile is the part of an Espanso match file needed to import it.
+type espansoFile struct {
+	Matches []struct {
+		Trigger  string   `yaml:"trigger"`
+		Triggers []string `yaml:"triggers"`
+		Replace  string   `yaml:"replace"`
+		Regex    string   `yaml:"regex"`
+		Vars     []any    `yaml:"vars"`
+	} `yaml:"matches"`
+}
+
+// importEspanso reads the matches from an Espanso match file. Matches that use
+// variables or regular expressions, or that do not replace text, are skipped.
+// Espanso triggers usually start with a colon, such as ":sig", but words end
+// at punctuation so these could never be typed. The colons at the start and
+// end of triggers are removed.
+func importEspanso(path string) (map[string]corrections.Entry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file espansoFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	imported := make(map[string]corrections.Entry)
+	for _, match := range file.Matches {
+		if match.Replace == "" || match.Regex != "" || len(match.Vars) > 0 {
+			continue
+		}
+		triggers := match.Triggers
+		if match.Trigger != "" {
+			triggers = append(triggers, match.Trigger)
+		}
+		for _, trigger := range triggers {
+			typo := strings.Trim(trigger, ":")
+			if typo == "" {
+				log.Warn().Str("trigger", trigger).Msg("Skipping match with only colons as its trigger.")
+				continue
+			}
+			if typo != trigger {
+				log.Debug().Str("trigger", trigger).Str("typo", typo).Msg("Removed colons from trigger.")
+			}
+			imported[typo] = corrections.Entry{Typo: typo, Correction: match.Replace}
+		}
+	}
+	return imported, nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		format   string
+		filename string
+		contents string
+		want     []corrections.Entry
+	}{
+		{
+			format:   "autohotkey",
+			filename: "hotstrings.ahk",
+			contents: "; comment\n" +
+				"::teh::the\n" +
+				":*:adn::and ; trailing comment\n" +
+				":C:Adn::And\n" +
+				":*C1:iso::ISO\n" +
+				":C0:wierd::weird\n" +
+				":?K10C:ot::to\n" +
+				":R:smiley:::-{)}\n" +
+				"::sig::Regards,`nMe\n" +
+				"::btw::{Shift down}by the way\n" +
+				":X:date::FormatTime\n" +
+				"::run::\n" +
+				"not a hotstring\n",
+			want: []corrections.Entry{
+				{Typo: "Adn", Correction: "And", Exact: true},
+				{Typo: "adn", Correction: "and"},
+				{Typo: "iso", Correction: "ISO", Verbatim: true},
+				{Typo: "ot", Correction: "to", Exact: true},
+				{Typo: "sig", Correction: "Regards,\nMe"},
+				{Typo: "smiley", Correction: ":-{)}"},
+				{Typo: "teh", Correction: "the"},
+				{Typo: "wierd", Correction: "weird"},
+			},
+		},
+		{
+			format:   "espanso",
+			filename: "base.yml",
+			contents: `matches:
+  - trigger: teh
+    replace: the
+  - trigger: ":sig"
+    replace: "Regards,\nMe"
+  - triggers: [":adr:", "addr"]
+    replace: 1 Main St
+  - trigger: ":"
+    replace: colon
+  - trigger: ":date"
+    replace: "{{today}}"
+    vars:
+      - name: today
+        type: date
+  - regex: ":t(?P<n>\\d+)"
+    replace: "{{n}}"
+  - trigger: ":img"
+    image_path: image.png
+`,
+			want: []corrections.Entry{
+				{Typo: "addr", Correction: "1 Main St"},
+				{Typo: "adr", Correction: "1 Main St"},
+				{Typo: "sig", Correction: "Regards,\nMe"},
+				{Typo: "teh", Correction: "the"},
+			},
+		},
+		{
+			format:   "codespell",
+			filename: "dictionary.txt",
+			contents: "# comment\n" +
+				"teh->the\n" +
+				" adn -> and \n" +
+				"abandonned->abandoned, abandon,\n" +
+				"clas->class, disabled because of name clash in c++\n" +
+				"nointeraction\n",
+			want: []corrections.Entry{
+				{Typo: "adn", Correction: "and"},
+				{Typo: "teh", Correction: "the"},
+			},
+		},
+		{
+			format:   "documentlist",
+			filename: "DocumentList.xml",
+			contents: `<?xml version="1.0" encoding="UTF-8"?>
+<block-list:block-list xmlns:block-list="http://openoffice.org/2001/block-list">
+  <block-list:block block-list:abbreviated-name="teh" block-list:name="the"/>
+  <block-list:block block-list:abbreviated-name="(c)" block-list:name="©"/>
+  <block-list:block block-list:abbreviated-name="empty" block-list:name=""/>
+</block-list:block-list>
+`,
+			want: []corrections.Entry{
+				{Typo: "(c)", Correction: "©"},
+				{Typo: "teh", Correction: "the"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if format, err := DetectFormat(path); err != nil || format != tt.format {
+				t.Errorf("DetectFormat(%s) = %q, %v, want %q", tt.filename, format, err, tt.format)
+			}
+			got, err := Import(tt.format, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imported %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportAutokey(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"teh.txt":          "the",
+		".teh.json":        `{"abbreviation": {"abbreviations": ["teh", "hte"]}}`,
+		"sub/adn.txt":      "and",
+		"sub/.adn.json":    `{"abbreviation": {"abbreviation": "adn"}}`,
+		"hotkey.txt":       "no abbreviation",
+		"nosettings.txt":   "no settings",
+		".hotkey.json":     `{"hotkey": {"hotKey": "a"}}`,
+		"invalid.txt":      "invalid",
+		".invalid.json":    `{`,
+		"sub/.folder.json": `{}`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := Import("autokey", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []corrections.Entry{
+		{Typo: "adn", Correction: "and"},
+		{Typo: "hte", Correction: "the"},
+		{Typo: "teh", Correction: "the"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("imported %+v, want %+v", got, want)
+	}
+}
+
+func TestMarshalTOML(t *testing.T) {
+	b, err := MarshalTOML([]corrections.Entry{
+		{Typo: "Adn", Correction: "And", Exact: true},
+		{Typo: "could of", Correction: "could have"},
+		{Typo: "iso", Correction: "ISO", Verbatim: true},
+		{Typo: "teh", Correction: "the"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the file must load as the same corrections
+	file := filepath.Join(t.TempDir(), "imported.toml")
+	if err := os.WriteFile(file, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := corrections.LoadFiles(file)
+	if err != nil {
+		t.Fatalf("could not load:\n%s\n%v", b, err)
+	}
+	for word, want := range map[string]string{
+		"Adn":      "And",
+		"adn":      "",
+		"iso":      "ISO",
+		"Iso":      "ISO",
+		"could of": "could have",
+		"Teh":      "The",
+	} {
+		got, ok := c.CheckWord(word)
+		if !ok {
+			got = ""
+		}
+		if got != want {
+			t.Errorf("CheckWord(%q) = %q, want %q, in:\n%s", word, got, want, b)
+		}
+	}
+}
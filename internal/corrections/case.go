@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// casePattern is the capitalisation of a typed word.
+type casePattern int
+
+const (
+	mixedCase casePattern = iota
+	lowerCase
+	titleCase
+	upperCase
+)
+
+// detectCase returns the capitalisation pattern of the given word. Only
+// letters are considered; a word without any letters is treated as lower
+// case.
+func detectCase(word string) casePattern {
+	var letters, upper int
+	var firstUpper bool
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.IsUpper(r) {
+			if letters == 0 {
+				firstUpper = true
+			}
+			upper++
+		}
+		letters++
+	}
+	switch {
+	case upper == 0:
+		return lowerCase
+	case firstUpper && upper == 1:
+		return titleCase
+	case upper == letters:
+		return upperCase
+	default:
+		return mixedCase
+	}
+}
+
+// applyCase re-applies the capitalisation pattern of the typed word to the
+// replacement. A replacement that already contains upper case letters (a
+// proper noun or acronym) is returned unchanged.
+func applyCase(typed, replacement string) string {
+	if strings.ToLower(replacement) != replacement {
+		return replacement
+	}
+	switch detectCase(typed) {
+	case titleCase:
+		r, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToTitle(r)) + replacement[size:]
+	case upperCase:
+		return strings.ToUpper(replacement)
+	default:
+		return replacement
+	}
+}
+
+// CaseVariants returns the corrections as they are made for each way a typo
+// can be capitalised when typed, for exporting to tools that match typos
+// case-sensitively. Entries that follow the capitalisation of the typed word
+// are expanded into their lower, title and upper case forms, with the
+// replacement capitalised to match as autocorrector would. Other entries, and
+// forms that are themselves entries, are kept as they are. All returned
+// entries are exact.
+func CaseVariants(entries []Entry) []Entry {
+	typos := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		typos[e.Typo] = true
+	}
+	var variants []Entry
+	for _, e := range entries {
+		followsCase := !e.Exact && strings.ToLower(e.Typo) == e.Typo
+		e.Exact = true
+		variants = append(variants, e)
+		if !followsCase {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(e.Typo)
+		for _, typo := range []string{string(unicode.ToTitle(r)) + e.Typo[size:], strings.ToUpper(e.Typo)} {
+			if typos[typo] {
+				continue
+			}
+			typos[typo] = true
+			variant := e
+			variant.Typo = typo
+			if !e.Verbatim {
+				variant.Correction = applyCase(typo, e.Correction)
+			}
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import "testing"
+
+func TestDetectCase(t *testing.T) {
+	tests := []struct {
+		word string
+		want casePattern
+	}{
+		{word: "teh", want: lowerCase},
+		{word: "Teh", want: titleCase},
+		{word: "TEH", want: upperCase},
+		{word: "tEh", want: mixedCase},
+		{word: "iPhone", want: mixedCase},
+		{word: "McDonald", want: mixedCase},
+		// a single capital letter is title case, so that a correction for
+		// it is not shouted
+		{word: "I", want: titleCase},
+		{word: "i", want: lowerCase},
+		{word: "I'M", want: upperCase},
+		{word: "Été", want: titleCase},
+		{word: "1st", want: lowerCase},
+		{word: "2ND", want: upperCase},
+		{word: "", want: lowerCase},
+	}
+	for _, tt := range tests {
+		if got := detectCase(tt.word); got != tt.want {
+			t.Errorf("detectCase(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCase(t *testing.T) {
+	tests := []struct {
+		typed, replacement, want string
+	}{
+		{typed: "teh", replacement: "the", want: "the"},
+		{typed: "Teh", replacement: "the", want: "The"},
+		{typed: "TEH", replacement: "the", want: "THE"},
+		{typed: "tEH", replacement: "the", want: "the"},
+		{typed: "U", replacement: "you", want: "You"},
+		{typed: "Iphone", replacement: "iPhone", want: "iPhone"},
+		{typed: "NASA", replacement: "NASA", want: "NASA"},
+		{typed: "Ecole", replacement: "école", want: "École"},
+		{typed: "Alot", replacement: "a lot", want: "A lot"},
+	}
+	for _, tt := range tests {
+		if got := applyCase(tt.typed, tt.replacement); got != tt.want {
+			t.Errorf("applyCase(%q, %q) = %q, want %q", tt.typed, tt.replacement, got, tt.want)
+		}
+	}
+}
@@ -6,58 +6,382 @@
 package corrections
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
-	"github.com/pelletier/go-toml/v2"
 	"github.com/rs/zerolog/log"
 )
 
 const (
 	correctionsFilename = "corrections.toml"
+	dropInDirname       = "corrections.d"
+	exactSection        = "exact"
 )
 
+// errNoCorrections is returned when none of the corrections files exist.
+var errNoCorrections = errors.New("no corrections files found")
+
+var (
+	systemPath = "/usr/share/autocorrector"
+	userPath   = filepath.Join(os.Getenv("HOME"), ".config", "autocorrector")
+)
+
+// entry is a single correction. An exact entry is only matched when the word
+// is typed exactly as it appears in the corrections file and its replacement
+// is always used verbatim. A verbatim entry is matched in any capitalisation,
+// but its replacement is always used verbatim.
+type entry struct {
+	replacement string
+	exact       bool
+	verbatim    bool
+	// apps limits the entry to the listed applications, if set.
+	apps []string
+	note string
+	// file is the corrections file the entry was loaded from.
+	file string
+}
+
+// list is a set of word corrections and patterns, either from a single file
+// or merged from several.
+type list struct {
+	words    map[string]entry
+	patterns []*pattern
+	snippets map[string]*snippet
+}
+
+func newList() *list {
+	return &list{
+		words:    make(map[string]entry),
+		snippets: make(map[string]*snippet),
+	}
+}
+
 type Corrections struct {
-	correctionsList map[string]string
-	mu              sync.Mutex
+	correctionsList *list
+	ignored         map[string]bool
+	// app holds the names of the application being typed into, for entries
+	// limited to certain applications.
+	app []string
+	// languages are the languages whose corrections files are loaded. If
+	// empty, the files for every language are loaded.
+	languages []string
+	// loaded holds the corrections list for each set of languages that has
+	// been loaded since the files last changed, keyed by the joined
+	// languages.
+	loaded map[string]*list
+	// files, if set, are the only corrections files used.
+	files []string
+	// onReload are called after the files are reloaded by Watch.
+	onReload []func()
+	mu       sync.Mutex
 }
 
+// SetApplication sets the application being typed into, by any of its names,
+// such as the class and instance of its window. Entries limited to certain
+// applications are only used when one of them is set.
+func (c *Corrections) SetApplication(names ...string) {
+	c.mu.Lock()
+	c.app = names
+	c.mu.Unlock()
+}
+
+// CheckWord returns the correction for the given word, if there is one. If the
+// word is not found as typed, its lower case form is looked up and the
+// capitalisation of the typed word (lower, Title or UPPER) is applied to the
+// replacement. If there is still no match, the word is checked against each
+// pattern in turn. Words on the ignore list are never corrected.
 func (c *Corrections) CheckWord(word string) (string, bool) {
 	c.mu.Lock()
-	correction, ok := c.correctionsList[word]
+	defer c.mu.Unlock()
+	if c.isIgnored(word) {
+		return "", false
+	}
+	if e, ok := c.correctionsList.words[word]; ok && e.inScope(c.app) {
+		return e.replacement, true
+	}
+	if detectCase(word) != mixedCase {
+		e, ok := c.correctionsList.words[strings.ToLower(word)]
+		if ok && !e.exact && e.inScope(c.app) {
+			if e.verbatim {
+				return e.replacement, true
+			}
+			return applyCase(word, e.replacement), true
+		}
+	}
+	// patterns only apply to single words, not phrases
+	if strings.ContainsRune(word, ' ') {
+		return "", false
+	}
+	for _, p := range c.correctionsList.patterns {
+		if correction, ok := p.apply(word); ok {
+			return correction, true
+		}
+	}
+	return "", false
+}
+
+// CheckSnippet returns the expansion of the snippet with the given trigger, if
+// there is one. Triggers are matched exactly. The returned offset is the number
+// of characters the caret should be moved back after typing the expansion.
+func (c *Corrections) CheckSnippet(word string) (string, int, bool) {
+	c.mu.Lock()
+	s, ok := c.correctionsList.snippets[word]
 	c.mu.Unlock()
-	return correction, ok
+	if !ok {
+		return "", 0, false
+	}
+	expansion, offset := s.expand()
+	return expansion, offset, true
+}
+
+// layerFiles returns the corrections files to load, in order of increasing
+// precedence. The system-wide list is the base, followed by any drop-in
+// files (system then user, each in lexical order) and finally the user's
+// personal corrections file. The system-wide and personal corrections files
+// can be in any supported format, and are loaded in the order of
+// formatExtensions if there is more than one. Files for a language not in
+// the given languages are left out, unless no languages are given.
+func layerFiles(languages []string) []string {
+	files := mainFiles(systemPath)
+	files = append(files, dropInFiles(systemPath)...)
+	files = append(files, dropInFiles(userPath)...)
+	files = append(files, mainFiles(userPath)...)
+	selected := files[:0]
+	for _, file := range files {
+		if inLanguages(file, languages) {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
+
+func dropInFiles(path string) []string {
+	matches, err := filepath.Glob(filepath.Join(path, dropInDirname, "*"))
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Could not list drop-in corrections.")
+		return nil
+	}
+	var files []string
+	for _, file := range matches {
+		if isCorrectionsFormat(file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// isDropIn reports whether file is in a drop-in directory.
+func isDropIn(file string) bool {
+	return filepath.Base(filepath.Dir(file)) == dropInDirname
 }
 
-// NewCorrections creates channels for sending words to check for corrections
-// (and signalling a config file reload) as well as a channel for recieving
-// corrected words
-func NewCorrections() (*Corrections, error) {
-	var correctionsFile string
-	var c []byte
-	var err error
-	corrections := &Corrections{
-		correctionsList: make(map[string]string),
+// loadLayers reads each of the given files and merges them into a single
+// corrections list. Entries in later files override those in earlier ones. An
+// entry with an empty replacement removes that word from the list, allowing a
+// higher layer to delete a correction defined in a lower one. Files that do
+// not exist are skipped, but at least one file must be loaded. A drop-in file
+// that cannot be loaded is skipped with a warning, as drop-in directories may
+// hold files that are not corrections, such as notes or files from other
+// tools; any other file that cannot be loaded fails the load.
+func loadLayers(files []string) (*list, error) {
+	merged := newList()
+	var loaded int
+	for _, file := range files {
+		layer, err := loadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Debug().Str("file", file).Msg("Corrections file not found, skipping.")
+			continue
+		}
+		if err != nil && isDropIn(file) {
+			log.Warn().Err(err).Str("file", file).Msg("Could not load drop-in corrections file, skipping.")
+			continue
+		}
+		if err != nil {
+			return nil, errors.Join(errors.New("could not load corrections file "+file), err)
+		}
+		merged.merge(layer)
+		loaded++
+		log.Info().Str("file", file).
+			Int("entries", len(layer.words)).
+			Int("patterns", len(layer.patterns)).
+			Int("snippets", len(layer.snippets)).
+			Msg("Opened corrections file.")
+	}
+	if loaded == 0 {
+		return nil, errNoCorrections
 	}
+	return merged, nil
+}
 
-	correctionsFile = filepath.Join(os.Getenv("HOME"), ".config/autocorrector/", correctionsFilename)
+// merge overlays the given list on top of this one. Patterns from the overlay
+// are tried before existing patterns. An overlay pattern with an empty
+// replacement removes the existing pattern with the same expression.
+func (l *list) merge(overlay *list) {
+	for word, e := range overlay.words {
+		if e.replacement == "" {
+			delete(l.words, word)
+			continue
+		}
+		l.words[word] = e
+	}
+	for trigger, s := range overlay.snippets {
+		if s == nil {
+			delete(l.snippets, trigger)
+			continue
+		}
+		l.snippets[trigger] = s
+	}
+	var patterns []*pattern
+	removed := make(map[string]bool)
+	for _, p := range overlay.patterns {
+		removed[p.match] = true
+		if p.replace != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	for _, p := range l.patterns {
+		if !removed[p.match] {
+			patterns = append(patterns, p)
+		}
+	}
+	l.patterns = patterns
+}
 
-	c, err = os.ReadFile(correctionsFile)
+func loadFile(file string) (*list, error) {
+	decode, ok := formats[filepath.Ext(file)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported corrections file format %q", filepath.Ext(file))
+	}
+	c, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decode(c)
+	if err != nil {
+		return nil, err
+	}
+	layer, err := parseLayer(doc)
 	if err != nil {
-		log.Warn().Err(err).Msg("Could not open personal corrections file. Will try system-wide one.")
+		return nil, err
+	}
+	for word, e := range layer.words {
+		e.file = file
+		layer.words[word] = e
+	}
+	return layer, nil
+}
+
+// parseLayer converts a decoded corrections file into a list of corrections.
+// Top-level keys are regular corrections, either a replacement or a table of
+// the replacement and its options. Keys in the exact section are
+// case-sensitive corrections and take precedence over a regular correction
+// for the same word. The patterns section is a list of regular expression
+// corrections and the snippets section is a table of text expansions.
+func parseLayer(doc map[string]any) (*list, error) {
+	layer := newList()
+	for word, value := range doc {
+		switch v := value.(type) {
+		case string:
+			layer.words[word] = entry{replacement: v}
+		case map[string]any:
+			switch word {
+			case exactSection:
+			case snippetsSection:
+				snippets, err := parseSnippets(v)
+				if err != nil {
+					return nil, err
+				}
+				layer.snippets = snippets
+			default:
+				e, err := parseEntry(word, v)
+				if err != nil {
+					return nil, err
+				}
+				layer.words[word] = e
+			}
+		case []any:
+			if word != patternsSection {
+				return nil, fmt.Errorf("unknown section %q", word)
+			}
+			patterns, err := parsePatterns(v)
+			if err != nil {
+				return nil, err
+			}
+			layer.patterns = patterns
+		default:
+			return nil, fmt.Errorf("invalid replacement for %q", word)
+		}
+	}
+	if exact, ok := doc[exactSection].(map[string]any); ok {
+		for word, value := range exact {
+			replacement, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid replacement for %q in section %q", word, exactSection)
+			}
+			layer.words[word] = entry{replacement: replacement, exact: true}
+		}
 	}
-	correctionsFile = filepath.Join("/usr/share/autocorrector", correctionsFilename)
-	c, err = os.ReadFile(correctionsFile)
+	return layer, nil
+}
+
+// NewCorrections loads and merges all available corrections files into a
+// single list of corrections. If languages are given, only the corrections
+// files for those languages, along with files that are not for any particular
+// language, are loaded. It is not an error for there to be no files for the
+// languages.
+func NewCorrections(languages ...string) (*Corrections, error) {
+	correctionsList, err := loadLanguages(layerFiles(languages), languages)
 	if err != nil {
 		return nil, err
 	}
+	ignored := make(map[string]bool)
+	if list, err := loadIgnoreList(); err != nil {
+		log.Warn().Err(err).Msg("Could not open ignore list, no words will be ignored.")
+	} else {
+		ignored = list.words()
+	}
+	return &Corrections{
+		correctionsList: correctionsList,
+		ignored:         ignored,
+		languages:       languages,
+		loaded:          map[string]*list{strings.Join(languages, ","): correctionsList},
+	}, nil
+}
 
-	err = toml.Unmarshal(c, &corrections.correctionsList)
+// LoadFiles creates corrections from only the given files, merged in order of
+// increasing precedence, instead of the system-wide and user's corrections
+// files. There is no ignore list.
+func LoadFiles(files ...string) (*Corrections, error) {
+	correctionsList, err := loadLayers(files)
 	if err != nil {
 		return nil, err
 	}
+	return &Corrections{
+		correctionsList: correctionsList,
+		ignored:         make(map[string]bool),
+		files:           files,
+		loaded:          map[string]*list{"": correctionsList},
+	}, nil
+}
 
-	log.Info().Str("file", correctionsFile).Msg("Opened corrections file.")
-	return corrections, nil
+// layerFiles returns the corrections files to load for the given languages,
+// either the files the corrections were created from or the usual layers.
+func (c *Corrections) layerFiles(languages []string) []string {
+	if c.files == nil {
+		return layerFiles(languages)
+	}
+	var files []string
+	for _, file := range c.files {
+		if inLanguages(file, languages) {
+			files = append(files, file)
+		}
+	}
+	return files
 }
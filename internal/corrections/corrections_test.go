@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadTestCorrections creates corrections from a single file with the given
+// contents.
+func loadTestCorrections(t *testing.T, toml string) *Corrections {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "corrections.toml")
+	if err := os.WriteFile(file, []byte(toml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadFiles(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSetApplication(t *testing.T) {
+	c := loadTestCorrections(t, `
+teh = 'the'
+
+[btw]
+replacement = 'by the way'
+apps = ['Firefox']
+`)
+	tests := []struct {
+		name string
+		app  []string
+		want bool
+	}{
+		{name: "unknown application", want: false},
+		{name: "other application", app: []string{"kitty", "kitty"}, want: false},
+		{name: "class", app: []string{"firefox", "Navigator"}, want: true},
+		{name: "instance", app: []string{"Navigator", "firefox"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.SetApplication(tt.app...)
+			if _, ok := c.CheckWord("btw"); ok != tt.want {
+				t.Errorf("btw corrected = %t, want %t", ok, tt.want)
+			}
+			if _, ok := c.CheckWord("teh"); !ok {
+				t.Error("teh not corrected")
+			}
+		})
+	}
+}
+
+func TestLayerOrder(t *testing.T) {
+	// each file corrects the same typo, and a typo of its own that is
+	// overridden by the next file in order
+	files := []struct {
+		dir, name, contents string
+	}{
+		{"system", "corrections.toml", "teh = 'system toml'\na = 'system toml'\n"},
+		{"system", "corrections.json", `{"teh": "system json", "a": "", "b": "system json"}`},
+		{"system", "corrections.d/a.tsv", "teh\tsystem a.tsv\nb\t\nc\tsystem a.tsv\n"},
+		{"system", "corrections.d/b.toml", "teh = 'system b.toml'\nc = ''\nd = 'system b.toml'\n"},
+		{"user", "corrections.d/a.json", `{"teh": "user a.json", "d": "", "e": "user a.json"}`},
+		{"user", "corrections.d/b.yaml", "teh: user b.yaml\ne: ''\nf: user b.yaml\n"},
+		{"user", "corrections.d/c.txt", "teh\tuser c.txt\nf\t\ng\tuser c.txt\n"},
+		{"user", "corrections.toml", "teh = 'user toml'\ng = ''\nh = 'user toml'\n"},
+		{"user", "corrections.yaml", "teh: user yaml\nh: ''\ni: user yaml\n"},
+		{"user", "corrections.tsv", "teh\tuser tsv\ni\t\n"},
+	}
+	system := make(map[string]string)
+	user := make(map[string]string)
+	for _, f := range files {
+		if f.dir == "system" {
+			system[f.name] = f.contents
+		} else {
+			user[f.name] = f.contents
+		}
+	}
+	useTestLayers(t, system, user)
+	var want []string
+	for _, f := range files {
+		dir := systemPath
+		if f.dir == "user" {
+			dir = userPath
+		}
+		want = append(want, filepath.Join(dir, filepath.FromSlash(f.name)))
+	}
+	if got := LayerFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LayerFiles() = %q, want %q", got, want)
+	}
+	c, err := NewCorrections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.CheckWord("teh"); got != "user tsv" {
+		t.Errorf("teh corrected to %q, want from the last file", got)
+	}
+	// every other typo is removed by the file after the one it is in
+	for _, typo := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"} {
+		if got, ok := c.CheckWord(typo); ok {
+			t.Errorf("%s corrected to %q, want removed by a later file", typo, got)
+		}
+	}
+}
+
+func TestDropInInvalid(t *testing.T) {
+	useTestLayers(t,
+		map[string]string{"corrections.toml": "teh = 'the'\n"},
+		map[string]string{
+			"corrections.d/notes.txt":  "not a correction\n",
+			"corrections.d/team.toml":  "adn = 'and'\n",
+			"corrections.d/zz.json":    "{",
+			"corrections.d/README.md":  "# not read\n",
+			"corrections.d/team.toml~": "backup = 'not read'\n",
+		})
+	c, err := NewCorrections()
+	if err != nil {
+		t.Fatalf("invalid drop-in files failed the load: %v", err)
+	}
+	for typo, want := range map[string]string{"teh": "the", "adn": "and"} {
+		if got, _ := c.CheckWord(typo); got != want {
+			t.Errorf("%s corrected to %q, want %q", typo, got, want)
+		}
+	}
+
+	// an invalid main file still fails the load
+	if err := os.WriteFile(filepath.Join(userPath, "corrections.toml"), []byte("teh = \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCorrections(); err == nil {
+		t.Error("invalid main file loaded")
+	}
+}
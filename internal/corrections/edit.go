@@ -0,0 +1,397 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Entry is a correction in the merged corrections list, along with the file
+// it comes from.
+type Entry struct {
+	Typo, Correction string
+	// Exact is true for entries in the exact section, or with the exact case
+	// option.
+	Exact bool
+	// Verbatim is true for entries with the verbatim case option.
+	Verbatim bool
+	// Apps are the applications the entry is limited to, if any.
+	Apps []string
+	Note string
+	File string
+}
+
+func newEntry(typo string, e entry) Entry {
+	return Entry{
+		Typo:       typo,
+		Correction: e.replacement,
+		Exact:      e.exact,
+		Verbatim:   e.verbatim,
+		Apps:       e.apps,
+		Note:       e.note,
+		File:       e.file,
+	}
+}
+
+// correctionsDoc is the user's corrections file, split up so that top-level
+// entries can be edited while keeping comments and sections intact.
+type correctionsDoc struct {
+	// header holds the lines before the first entry, such as a comment
+	// describing the file.
+	header []string
+	// entries are the top-level entries, each with the comment lines directly
+	// above it.
+	entries map[string][]string
+	// sections holds everything from the first table header onwards.
+	sections []string
+	// tables holds the typos of entries written as tables, with options,
+	// which are not edited.
+	tables map[string]bool
+}
+
+func userCorrectionsFile() string {
+	return filepath.Join(userPath, correctionsFilename)
+}
+
+func isComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// parseCorrectionsDoc splits a corrections file into its header, top-level
+// entries and sections. Entries must each be on a single line.
+func parseCorrectionsDoc(b []byte) (*correctionsDoc, error) {
+	doc := &correctionsDoc{entries: make(map[string][]string), tables: make(map[string]bool)}
+	var pending []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		switch {
+		case doc.sections != nil:
+			doc.sections = append(doc.sections, line)
+			doc.addTable(line)
+		case strings.HasPrefix(strings.TrimSpace(line), "["):
+			pending = doc.splitHeader(pending)
+			doc.sections = append(pending, line)
+			doc.addTable(line)
+			pending = nil
+		case isComment(line):
+			pending = append(pending, line)
+		default:
+			var kv map[string]any
+			if err := toml.Unmarshal([]byte(line), &kv); err != nil || len(kv) != 1 {
+				return nil, fmt.Errorf("could not edit line %d, entries must be on a single line", n)
+			}
+			pending = doc.splitHeader(pending)
+			for key, value := range kv {
+				if _, ok := value.(string); !ok {
+					doc.tables[key] = true
+				}
+				doc.entries[key] = append(withoutBlanks(pending), line)
+			}
+			pending = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(doc.entries) == 0 {
+		// a file of only comments
+		doc.header = pending
+	} else if comments := withoutBlanks(pending); len(comments) > 0 {
+		// comments after the last entry are kept at the end
+		doc.sections = comments
+	}
+	return doc, nil
+}
+
+// splitHeader moves the comments at the start of the file, separated from the
+// first entry or section by a blank line, into the header. They describe the
+// file rather than what follows them. The remaining comments are returned.
+func (d *correctionsDoc) splitHeader(pending []string) []string {
+	if len(d.entries) > 0 || d.header != nil {
+		return pending
+	}
+	for i := len(pending) - 1; i >= 0; i-- {
+		if strings.TrimSpace(pending[i]) == "" {
+			d.header = pending[:i+1]
+			return pending[i+1:]
+		}
+	}
+	return pending
+}
+
+func withoutBlanks(lines []string) []string {
+	var kept []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// addTable records the typo of an entry table from a table header line.
+func (d *correctionsDoc) addTable(line string) {
+	var header map[string]any
+	if toml.Unmarshal([]byte(line), &header) != nil {
+		return
+	}
+	for key := range header {
+		if key != exactSection && key != snippetsSection && key != patternsSection {
+			d.tables[key] = true
+		}
+	}
+}
+
+// set adds or replaces the top-level entry for typo, keeping any comments
+// above an existing entry.
+func (d *correctionsDoc) set(typo, correction string) error {
+	if d.tables[typo] {
+		return fmt.Errorf("%q has options, edit the corrections file to change it", typo)
+	}
+	b, err := toml.Marshal(map[string]string{typo: correction})
+	if err != nil {
+		return err
+	}
+	line := strings.TrimRight(string(b), "\n")
+	lines := d.entries[typo]
+	if len(lines) > 0 {
+		lines = lines[:len(lines)-1]
+	}
+	d.entries[typo] = append(lines, line)
+	return nil
+}
+
+// bytes writes out the document with the top-level entries sorted.
+func (d *correctionsDoc) bytes() []byte {
+	var out bytes.Buffer
+	for _, line := range d.header {
+		out.WriteString(line + "\n")
+	}
+	keys := make([]string, 0, len(d.entries))
+	for key := range d.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, line := range d.entries[key] {
+			out.WriteString(line + "\n")
+		}
+	}
+	sections := d.sections
+	for len(sections) > 0 && strings.TrimSpace(sections[0]) == "" {
+		sections = sections[1:]
+	}
+	if len(sections) > 0 {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		for _, line := range sections {
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.Bytes()
+}
+
+// editUserCorrections applies an edit to the user's corrections file, creating
+// it if needed. The file is replaced atomically, so a running instance will
+// only ever reload a complete file.
+func editUserCorrections(edit func(doc *correctionsDoc) error) error {
+	file := userCorrectionsFile()
+	b, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	doc, err := parseCorrectionsDoc(b)
+	if err != nil {
+		return errors.Join(errors.New("could not edit corrections file "+file), err)
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(userPath, 0o750); err != nil {
+		return err
+	}
+	return writeFileAtomic(file, doc.bytes())
+}
+
+// setUserCorrections adds the given corrections to the user's corrections
+// file, replacing any existing entries for the same typos. Nothing is changed
+// if any of the typos come from a file that takes precedence over the user's
+// corrections file, as the correction added would never be used.
+func setUserCorrections(corrections map[string]string) error {
+	typos := make([]string, 0, len(corrections))
+	for typo := range corrections {
+		typos = append(typos, typo)
+	}
+	sort.Strings(typos)
+	return editUserCorrections(func(doc *correctionsDoc) error {
+		_, higher, err := otherLayers()
+		if err != nil {
+			return err
+		}
+		for _, typo := range typos {
+			if e, ok := higher.words[typo]; ok {
+				return fmt.Errorf("%q comes from %s, which takes precedence over the user's corrections file, edit it to change the correction", typo, e.file)
+			}
+			if err := doc.set(typo, corrections[typo]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddCorrection adds a correction to the user's corrections file, replacing
+// any existing correction for the same typo. It fails if the typo comes from a
+// file that takes precedence over the user's corrections file.
+func AddCorrection(typo, correction string) error {
+	if typo == "" || strings.TrimSpace(typo) != typo {
+		return fmt.Errorf("invalid typo %q", typo)
+	}
+	if correction == "" {
+		return errors.New("correction cannot be empty")
+	}
+	return setUserCorrections(map[string]string{typo: correction})
+}
+
+// RemoveCorrection removes the correction for typo. If it is only in the
+// user's corrections file, it is deleted from there. If it comes from a lower
+// layer, such as the system-wide list or a drop-in file, the files are left
+// as they are and an empty replacement is added to the user's corrections
+// file to override it. The file the overridden correction comes from is
+// returned in that case.
+func RemoveCorrection(typo string) (string, error) {
+	var overridden string
+	err := editUserCorrections(func(doc *correctionsDoc) error {
+		lower, higher, err := otherLayers()
+		if err != nil {
+			return err
+		}
+		if e, ok := higher.words[typo]; ok {
+			return fmt.Errorf("%q comes from %s, which takes precedence over the user's corrections file, edit it to remove the correction", typo, e.file)
+		}
+		e, ok := lower.words[typo]
+		if !ok {
+			if _, ok := doc.entries[typo]; !ok && !doc.tables[typo] {
+				return fmt.Errorf("no correction for %q", typo)
+			}
+			if doc.tables[typo] {
+				// reports that the entry has to be edited by hand
+				return doc.set(typo, "")
+			}
+			delete(doc.entries, typo)
+			return nil
+		}
+		overridden = e.file
+		return doc.set(typo, "")
+	})
+	if err != nil {
+		return "", err
+	}
+	return overridden, nil
+}
+
+// otherLayers merges the corrections files other than the user's corrections
+// file, split into those it overrides and those that override it.
+func otherLayers() (lower, higher *list, err error) {
+	var lowerFiles, higherFiles []string
+	files := &lowerFiles
+	for _, file := range layerFiles(nil) {
+		if file == userCorrectionsFile() {
+			files = &higherFiles
+			continue
+		}
+		*files = append(*files, file)
+	}
+	if lower, err = loadLayers(lowerFiles); errors.Is(err, errNoCorrections) {
+		lower, err = newList(), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if higher, err = loadLayers(higherFiles); errors.Is(err, errNoCorrections) {
+		higher, err = newList(), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return lower, higher, nil
+}
+
+// ListCorrections returns all word and phrase corrections from the merged
+// corrections files, sorted by typo. If languages are given, only the
+// corrections used for those languages are returned.
+func ListCorrections(languages ...string) ([]Entry, error) {
+	merged, err := loadLanguages(layerFiles(languages), languages)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(merged.words))
+	for typo, e := range merged.words {
+		entries = append(entries, newEntry(typo, e))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Typo < entries[j].Typo
+	})
+	return entries, nil
+}
+
+// SearchCorrections returns the corrections whose typo or correction contains
+// the query, ignoring case.
+func SearchCorrections(query string, languages ...string) ([]Entry, error) {
+	entries, err := ListCorrections(languages...)
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(query)
+	var found []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Typo), query) || strings.Contains(strings.ToLower(e.Correction), query) {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+// ShowCorrection returns the correction for a word and where it comes from.
+// As when typing, a word that is not found as given is looked up in lower
+// case.
+func ShowCorrection(word string, languages ...string) (*Entry, error) {
+	merged, err := loadLanguages(layerFiles(languages), languages)
+	if err != nil {
+		return nil, err
+	}
+	typo := word
+	e, ok := merged.words[typo]
+	if !ok && detectCase(word) != mixedCase {
+		typo = strings.ToLower(word)
+		if e, ok = merged.words[typo]; ok && e.exact {
+			ok = false
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("no correction for %q", word)
+	}
+	found := newEntry(typo, e)
+	if typo != word && !e.verbatim {
+		found.Correction = applyCase(word, e.replacement)
+	}
+	return &found, nil
+}
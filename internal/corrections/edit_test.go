@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestLayers points the system-wide and user's corrections at temporary
+// directories for the test, writing the given files into them.
+func useTestLayers(t *testing.T, system, user map[string]string) {
+	t.Helper()
+	oldSystem, oldUser := systemPath, userPath
+	systemPath, userPath = t.TempDir(), t.TempDir()
+	t.Cleanup(func() { systemPath, userPath = oldSystem, oldUser })
+	for dir, files := range map[string]map[string]string{systemPath: system, userPath: user} {
+		for name, contents := range files {
+			file := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRemoveCorrection(t *testing.T) {
+	useTestLayers(t,
+		map[string]string{"corrections.toml": "teh = \"the\"\nadn = \"and\"\n"},
+		map[string]string{
+			"corrections.toml":    "teh = \"teh\"\nwoudl = \"would\"\n",
+			"corrections.de.toml": "nciht = \"nicht\"\n",
+		})
+
+	overridden, err := RemoveCorrection("woudl")
+	if err != nil || overridden != "" {
+		t.Errorf("RemoveCorrection(woudl) = %q, %v, want deleted from user file", overridden, err)
+	}
+	for _, typo := range []string{"teh", "adn"} {
+		overridden, err := RemoveCorrection(typo)
+		if want := filepath.Join(systemPath, "corrections.toml"); err != nil || overridden != want {
+			t.Errorf("RemoveCorrection(%s) = %q, %v, want %q overridden", typo, overridden, err, want)
+		}
+	}
+	if _, err := RemoveCorrection("nciht"); err == nil {
+		t.Error("RemoveCorrection(nciht) from a higher layer succeeded")
+	}
+	if _, err := RemoveCorrection("missing"); err == nil {
+		t.Error("RemoveCorrection(missing) succeeded")
+	}
+
+	b, err := os.ReadFile(userCorrectionsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "adn = ''\nteh = ''\n"; got != want {
+		t.Errorf("user corrections file is %q, want %q", got, want)
+	}
+	entries, err := ListCorrections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Typo != "nciht" {
+		t.Errorf("corrections left are %v, want only nciht", entries)
+	}
+}
+
+func TestAddCorrection(t *testing.T) {
+	useTestLayers(t,
+		map[string]string{"corrections.toml": "teh = \"the\"\n"},
+		map[string]string{
+			"corrections.toml":    "# my corrections\n\nadn = \"and\"\n",
+			"corrections.de.toml": "nciht = \"nicht\"\n",
+		})
+
+	for typo, correction := range map[string]string{"teh": "tea", "adn": "AND", "woudl": "would"} {
+		if err := AddCorrection(typo, correction); err != nil {
+			t.Errorf("AddCorrection(%s) failed: %v", typo, err)
+		}
+	}
+	err := AddCorrection("nciht", "nix")
+	if want := filepath.Join(userPath, "corrections.de.toml"); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("AddCorrection(nciht) from a higher layer = %v, want error naming %s", err, want)
+	}
+	for _, typo := range []string{"", " teh"} {
+		if err := AddCorrection(typo, "the"); err == nil {
+			t.Errorf("AddCorrection(%q) succeeded", typo)
+		}
+	}
+	if err := AddCorrection("teh", ""); err == nil {
+		t.Error("AddCorrection with an empty correction succeeded")
+	}
+
+	b, err := os.ReadFile(userCorrectionsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "# my corrections\n\nadn = 'AND'\nteh = 'tea'\nwoudl = 'would'\n"; got != want {
+		t.Errorf("user corrections file is %q, want %q", got, want)
+	}
+}
+
+func TestAcceptSuggestionsHigherLayer(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.de.toml": "nciht = \"nicht\"\n"})
+	c, err := NewCorrections("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < learnThreshold; i++ {
+		c.Learn("nciht", "nicht")
+	}
+	if err := AcceptSuggestions(); err == nil {
+		t.Error("accepted a suggestion for a typo in a higher layer")
+	}
+	// the suggestion is kept, and the user's corrections file not created
+	if suggestions, err := Suggestions(); err != nil || len(suggestions) != 1 {
+		t.Errorf("pending suggestions are %v, %v", suggestions, err)
+	}
+	if _, err := os.Stat(userCorrectionsFile()); !os.IsNotExist(err) {
+		t.Errorf("user corrections file written: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"fmt"
+	"strings"
+)
+
+// How the capitalisation of a typed word is handled for an entry, set with
+// the case option of an entry table.
+const (
+	// casePreserve matches the typo in any capitalisation and applies the
+	// capitalisation of the typed word to the replacement. This is the
+	// default.
+	casePreserve = "preserve"
+	// caseExact only matches the typo exactly as written and uses the
+	// replacement verbatim, as for entries in the exact section.
+	caseExact = "exact"
+	// caseVerbatim matches the typo in any capitalisation but always uses the
+	// replacement exactly as written.
+	caseVerbatim = "verbatim"
+)
+
+// parseEntry reads an entry written as a table, such as:
+//
+//	[teh]
+//	replacement = "the"
+//	case = "preserve"
+//	apps = ["firefox"]
+//	enabled = true
+//	note = "..."
+//
+// Only the replacement is required. A disabled entry is returned with an
+// empty replacement so that, like an empty replacement, it removes the word
+// from lower layers.
+func parseEntry(word string, table map[string]any) (entry, error) {
+	var e entry
+	enabled := true
+	for key, value := range table {
+		var ok bool
+		switch key {
+		case "replacement":
+			e.replacement, ok = value.(string)
+		case "case":
+			var c string
+			if c, ok = value.(string); ok {
+				switch c {
+				case casePreserve:
+				case caseExact:
+					e.exact = true
+				case caseVerbatim:
+					e.verbatim = true
+				default:
+					return entry{}, fmt.Errorf("invalid case %q for %q, must be one of %s, %s or %s",
+						c, word, casePreserve, caseExact, caseVerbatim)
+				}
+			}
+		case "apps":
+			var apps []any
+			if apps, ok = value.([]any); ok {
+				for _, app := range apps {
+					name, isString := app.(string)
+					if !isString {
+						ok = false
+						break
+					}
+					e.apps = append(e.apps, name)
+				}
+			}
+		case "enabled":
+			enabled, ok = value.(bool)
+		case "note":
+			e.note, ok = value.(string)
+		default:
+			return entry{}, fmt.Errorf("unknown option %q for %q", key, word)
+		}
+		if !ok {
+			return entry{}, fmt.Errorf("invalid %s for %q", key, word)
+		}
+	}
+	if !enabled {
+		return entry{note: e.note}, nil
+	}
+	if e.replacement == "" {
+		return entry{}, fmt.Errorf("missing replacement for %q", word)
+	}
+	return e, nil
+}
+
+// inScope reports whether an entry applies to the application with the given
+// names. Entries limited to some applications never apply when the
+// application is unknown.
+func (e entry) inScope(names []string) bool {
+	if len(e.apps) == 0 {
+		return true
+	}
+	for _, a := range e.apps {
+		for _, name := range names {
+			if strings.EqualFold(a, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// correctionsBasename is the name, without the extension, of the main
+// corrections file in each directory.
+const correctionsBasename = "corrections"
+
+// decoder converts the contents of a corrections file into the same
+// representation as a decoded TOML file.
+type decoder func(b []byte) (map[string]any, error)
+
+// formats are the supported corrections file formats, by file extension.
+var formats = map[string]decoder{
+	".toml": decodeTOML,
+	".yaml": decodeYAML,
+	".yml":  decodeYAML,
+	".json": decodeJSON,
+	".tsv":  decodeText,
+	".txt":  decodeText,
+}
+
+// formatExtensions is the order in which corrections files in the same
+// directory with different formats are loaded.
+var formatExtensions = []string{".toml", ".yaml", ".yml", ".json", ".tsv", ".txt"}
+
+func isCorrectionsFormat(path string) bool {
+	_, ok := formats[filepath.Ext(path)]
+	return ok
+}
+
+// mainFiles returns the main corrections file in the directory in each of
+// the supported formats, followed by any main files for a single language
+// (such as corrections.de.toml), in lexical order.
+func mainFiles(path string) []string {
+	files := make([]string, 0, len(formatExtensions))
+	for _, ext := range formatExtensions {
+		files = append(files, filepath.Join(path, correctionsBasename+ext))
+	}
+	var tagged []string
+	for _, ext := range formatExtensions {
+		matches, err := filepath.Glob(filepath.Join(path, correctionsBasename+".*"+ext))
+		if err != nil {
+			continue
+		}
+		for _, file := range matches {
+			if fileLanguage(file) != "" {
+				tagged = append(tagged, file)
+			}
+		}
+	}
+	sort.Strings(tagged)
+	return append(files, tagged...)
+}
+
+func decodeTOML(b []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decodeYAML(b []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decodeJSON(b []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decodeText reads a plain text corrections file, with a typo and its
+// correction separated by a tab on each line. Blank lines and lines starting
+// with # are ignored. Only plain corrections are supported.
+func decodeText(b []byte) (map[string]any, error) {
+	doc := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		typo, correction, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("line %d: typo and correction must be separated by a tab", n)
+		}
+		doc[typo] = strings.TrimSuffix(correction, "\r")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
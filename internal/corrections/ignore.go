@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ignoreFilename = "ignore.toml"
+	// ignoreThreshold is how many times a correction must be reverted before
+	// the word is added to the ignore list.
+	ignoreThreshold = 2
+)
+
+// ignoreList holds the words that should never be corrected and how many
+// times corrections for other words have been reverted. Words are stored in
+// lower case so that they are ignored however they are capitalised.
+type ignoreList struct {
+	Ignore   []string       `toml:"ignore"`
+	Rejected map[string]int `toml:"rejected,omitempty"`
+}
+
+func ignoreFile() string {
+	return filepath.Join(userPath, ignoreFilename)
+}
+
+func loadIgnoreList() (*ignoreList, error) {
+	list := &ignoreList{Rejected: make(map[string]int)}
+	b, err := os.ReadFile(ignoreFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := toml.Unmarshal(b, list); err != nil {
+		return nil, err
+	}
+	if list.Rejected == nil {
+		list.Rejected = make(map[string]int)
+	}
+	return list, nil
+}
+
+func (l *ignoreList) save() error {
+	sort.Strings(l.Ignore)
+	b, err := toml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ignoreFile(), b)
+}
+
+func (l *ignoreList) words() map[string]bool {
+	words := make(map[string]bool, len(l.Ignore))
+	for _, word := range l.Ignore {
+		// the file may have been edited by hand
+		words[strings.ToLower(word)] = true
+	}
+	return words
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory as
+// the given file and renames it into place, so that readers never see a
+// partially written file.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o640); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// ignoreMu serialises changes to the ignore file, so that the ignore list is
+// not locked while it is read and written.
+var ignoreMu sync.Mutex
+
+// Reject records that a correction for the word was reverted by the user. Once
+// the corrections for a word have been reverted enough times, the word is
+// added to the ignore list and will no longer be corrected.
+func (c *Corrections) Reject(word string) {
+	word = strings.ToLower(word)
+	ignoreMu.Lock()
+	defer ignoreMu.Unlock()
+	list, err := loadIgnoreList()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not open ignore list.")
+		return
+	}
+	list.Rejected[word]++
+	if list.Rejected[word] >= ignoreThreshold {
+		delete(list.Rejected, word)
+		list.Ignore = append(list.Ignore, word)
+		log.Info().Str("word", word).Msg("Adding word to ignore list.")
+	}
+	if err := list.save(); err != nil {
+		log.Warn().Err(err).Msg("Could not save ignore list.")
+		return
+	}
+	ignored := list.words()
+	c.mu.Lock()
+	c.ignored = ignored
+	c.mu.Unlock()
+}
+
+func (c *Corrections) isIgnored(word string) bool {
+	return c.ignored[strings.ToLower(word)]
+}
+
+// IsIgnored reports whether the word is on the ignore list.
+func (c *Corrections) IsIgnored(word string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.isIgnored(word)
+}
+
+// Ignored returns the words on the ignore list.
+func Ignored() ([]string, error) {
+	list, err := loadIgnoreList()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(list.Ignore)
+	return list.Ignore, nil
+}
+
+// ClearIgnored empties the ignore list and forgets any reverted corrections. A
+// running autocorrector will pick up the change automatically.
+func ClearIgnored() error {
+	if err := os.Remove(ignoreFile()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// languageRe matches a language tag in the name of a corrections file, such
+// as the de in corrections.de.toml: a language code, optionally followed by a
+// region (en_GB).
+var languageRe = regexp.MustCompile(`^([a-z]{2})(_[A-Z]{2})?$`)
+
+// languageCodes are the ISO 639-1 language codes. Only these are taken as
+// languages, so that other tags, such as in corrections.old.toml or
+// team.dev.toml, are not mistaken for one.
+var languageCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, code := range strings.Fields(`
+	aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca
+	ce ch co cr cs cu cv cy da de dv dz ee el en eo es et eu fa ff fi fj
+	fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu hy hz ia id ie ig ii
+	ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la
+	lb lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng
+	nl nn no nr nv ny oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa
+	sc sd se sg si sk sl sm sn so sq sr ss st su sv sw ta te tg th ti tk
+	tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu
+	`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// fileLanguage returns the language a corrections file is for, from the tag
+// before its extension, or an empty string if the file applies to all
+// languages.
+func fileLanguage(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	tag := strings.TrimPrefix(filepath.Ext(name), ".")
+	if m := languageRe.FindStringSubmatch(tag); m == nil || !languageCodes[m[1]] {
+		return ""
+	}
+	return tag
+}
+
+// inLanguages reports whether a corrections file should be loaded for the
+// given languages. Files without a language are always loaded, as are all
+// files when no languages are given.
+func inLanguages(file string, languages []string) bool {
+	lang := fileLanguage(file)
+	if lang == "" || len(languages) == 0 {
+		return true
+	}
+	for _, l := range languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableLanguages returns the languages that there are corrections files
+// for, in any layer.
+func AvailableLanguages() []string {
+	found := make(map[string]bool)
+	for _, file := range layerFiles(nil) {
+		if lang := fileLanguage(file); lang != "" {
+			found[lang] = true
+		}
+	}
+	languages := make([]string, 0, len(found))
+	for lang := range found {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ActiveLanguages returns the languages whose corrections are being used. If
+// none are returned, the corrections for every language are used.
+func (c *Corrections) ActiveLanguages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.languages...)
+}
+
+// SetLanguages changes the languages whose corrections are used. If no
+// languages are given, the corrections for every language are used.
+// Corrections files without a language are always used. The corrections for
+// each set of languages are kept once loaded, so switching back and forth is
+// quick. If the corrections cannot be loaded, the current languages are kept.
+func (c *Corrections) SetLanguages(languages ...string) {
+	key := strings.Join(languages, ",")
+	c.mu.Lock()
+	if strings.Join(c.languages, ",") == key {
+		c.mu.Unlock()
+		return
+	}
+	if cached, ok := c.loaded[key]; ok {
+		c.languages = languages
+		c.correctionsList = cached
+		c.mu.Unlock()
+		log.Debug().Strs("languages", languages).Msg("Changed corrections languages.")
+		return
+	}
+	c.mu.Unlock()
+	log.Info().Strs("languages", languages).Msg("Changing corrections languages.")
+	if err := c.load(languages); err != nil {
+		log.Warn().Err(err).Strs("languages", languages).
+			Msg("Could not load corrections for languages, keeping current languages.")
+	}
+}
+
+// loadLanguages merges the corrections files for the given languages. There
+// may be no files at all for the languages, in which case there are no
+// corrections to make rather than an error.
+func loadLanguages(files, languages []string) (*list, error) {
+	merged, err := loadLayers(files)
+	if errors.Is(err, errNoCorrections) && len(languages) > 0 {
+		log.Warn().Strs("languages", languages).Msg("No corrections files for languages, no corrections will be made.")
+		return newList(), nil
+	}
+	return merged, err
+}
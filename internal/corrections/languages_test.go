@@ -0,0 +1,96 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetLanguages(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.en.toml": "teh = 'the'\n"})
+	c, err := NewCorrections("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(wantLanguages []string, wantCorrected bool) {
+		t.Helper()
+		if got := c.ActiveLanguages(); !reflect.DeepEqual(got, wantLanguages) {
+			t.Errorf("active languages are %v, want %v", got, wantLanguages)
+		}
+		if _, ok := c.CheckWord("teh"); ok != wantCorrected {
+			t.Errorf("teh corrected = %t, want %t", ok, wantCorrected)
+		}
+	}
+	check([]string{"en"}, true)
+
+	// there are no files for de, so there are no corrections
+	c.SetLanguages("de")
+	check([]string{"de"}, false)
+
+	c.SetLanguages("en")
+	check([]string{"en"}, true)
+
+	// a broken file keeps the current languages and corrections
+	useTestLayers(t, nil, map[string]string{"corrections.it.toml": "teh = [\n"})
+	c.SetLanguages("it")
+	check([]string{"en"}, true)
+}
+
+func TestNewCorrectionsNoFiles(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.en.toml": "teh = 'the'\n"})
+	c, err := NewCorrections("de")
+	if err != nil {
+		t.Fatalf("NewCorrections(de) failed: %v", err)
+	}
+	if _, ok := c.CheckWord("teh"); ok {
+		t.Error("teh corrected without its language")
+	}
+}
+
+func TestFileLanguage(t *testing.T) {
+	for file, want := range map[string]string{
+		"corrections.toml":             "",
+		"corrections.de.toml":          "de",
+		"corrections.en_GB.yaml":       "en_GB",
+		"corrections.d/team.fr.json":   "fr",
+		"corrections.d/team.toml":      "",
+		"corrections.old.toml":         "",
+		"corrections.d/team.dev.toml":  "",
+		"corrections.d/team.qq.toml":   "",
+		"corrections.en_gb.toml":       "",
+		"corrections.EN.toml":          "",
+		"corrections.d/my.notes.txt":   "",
+		"corrections.d/2023.it.tsv":    "it",
+		"corrections.d/team.en_XYZ.ts": "",
+	} {
+		if got := fileLanguage(file); got != want {
+			t.Errorf("fileLanguage(%s) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestUnknownLanguageTags(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{
+		"corrections.de.toml":         "nciht = 'nicht'\n",
+		"corrections.old.toml":        "old = 'not loaded'\n",
+		"corrections.d/team.dev.toml": "teh = 'the'\n",
+	})
+	if got, want := AvailableLanguages(), []string{"de"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AvailableLanguages() = %v, want %v", got, want)
+	}
+	c, err := NewCorrections("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// team.dev.toml is not for a language, so is used for every language,
+	// and corrections.old.toml is not a main corrections file
+	for typo, want := range map[string]bool{"teh": true, "nciht": false, "old": false} {
+		if _, ok := c.CheckWord(typo); ok != want {
+			t.Errorf("%s corrected = %t, want %t", typo, ok, want)
+		}
+	}
+}
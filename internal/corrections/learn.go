@@ -0,0 +1,225 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	learnedFilename = "learned.toml"
+	// learnThreshold is how many times a word must be retyped as the same
+	// correction before that correction is suggested.
+	learnThreshold = 3
+	// minLearnLength is the shortest typo that will be learnt. Shorter words
+	// are too often partially typed words rather than typos.
+	minLearnLength = 3
+)
+
+// learnedList holds candidate corrections observed from the user retyping
+// words, corrections that have been seen often enough to be suggested, and
+// typos whose suggestions have been dismissed.
+type learnedList struct {
+	Candidates map[string]map[string]int `toml:"candidates,omitempty"`
+	Pending    map[string]string         `toml:"pending,omitempty"`
+	Dismissed  []string                  `toml:"dismissed,omitempty"`
+}
+
+// Suggestion is a correction learnt from the user retyping a word.
+type Suggestion struct {
+	Typo, Correction string
+}
+
+// learnedMu serialises changes to the learned file, so that the corrections
+// are not locked while it is read and written.
+var learnedMu sync.Mutex
+
+func learnedFile() string {
+	return filepath.Join(userPath, learnedFilename)
+}
+
+func loadLearnedList() (*learnedList, error) {
+	list := &learnedList{}
+	b, err := os.ReadFile(learnedFile())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := toml.Unmarshal(b, list); err != nil {
+			return nil, err
+		}
+	}
+	if list.Candidates == nil {
+		list.Candidates = make(map[string]map[string]int)
+	}
+	if list.Pending == nil {
+		list.Pending = make(map[string]string)
+	}
+	return list, nil
+}
+
+func (l *learnedList) save() error {
+	sort.Strings(l.Dismissed)
+	b, err := toml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(learnedFile(), b)
+}
+
+func (l *learnedList) isDismissed(typo string) bool {
+	for _, dismissed := range l.Dismissed {
+		if dismissed == typo {
+			return true
+		}
+	}
+	return false
+}
+
+// isLearnable reports whether retyping typo as correction looks like fixing a
+// typo, rather than rewording: both must be words of a reasonable length and
+// differ by only a small edit.
+func isLearnable(typo, correction string) bool {
+	if typo == correction || utf8.RuneCountInString(typo) < minLearnLength {
+		return false
+	}
+	for _, r := range typo + correction {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	maxDistance := 1
+	if utf8.RuneCountInString(correction) > 4 {
+		maxDistance = 2
+	}
+	return distance(typo, correction) <= maxDistance
+}
+
+// Learn records that the user deleted typo and typed correction in its place.
+// Once the same pair has been seen enough times it becomes a pending
+// suggestion, which is returned so that it can be shown to the user.
+func (c *Corrections) Learn(typo, correction string) (*Suggestion, bool) {
+	if !isLearnable(typo, correction) {
+		return nil, false
+	}
+	c.mu.Lock()
+	_, known := c.correctionsList.words[typo]
+	c.mu.Unlock()
+	if known {
+		return nil, false
+	}
+	learnedMu.Lock()
+	defer learnedMu.Unlock()
+	list, err := loadLearnedList()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not open learned corrections.")
+		return nil, false
+	}
+	if _, ok := list.Pending[typo]; ok || list.isDismissed(typo) {
+		return nil, false
+	}
+	if list.Candidates[typo] == nil {
+		list.Candidates[typo] = make(map[string]int)
+	}
+	list.Candidates[typo][correction]++
+	log.Debug().Str("typo", typo).Str("correction", correction).
+		Int("count", list.Candidates[typo][correction]).
+		Msg("Observed retyped word.")
+	var suggestion *Suggestion
+	if list.Candidates[typo][correction] >= learnThreshold {
+		delete(list.Candidates, typo)
+		list.Pending[typo] = correction
+		suggestion = &Suggestion{Typo: typo, Correction: correction}
+	}
+	if err := list.save(); err != nil {
+		log.Warn().Err(err).Msg("Could not save learned corrections.")
+		return nil, false
+	}
+	return suggestion, suggestion != nil
+}
+
+// Suggestions returns the pending suggested corrections, sorted by typo.
+func Suggestions() ([]Suggestion, error) {
+	list, err := loadLearnedList()
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]Suggestion, 0, len(list.Pending))
+	for typo, correction := range list.Pending {
+		suggestions = append(suggestions, Suggestion{Typo: typo, Correction: correction})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Typo < suggestions[j].Typo
+	})
+	return suggestions, nil
+}
+
+// AcceptSuggestions adds the pending suggestions for the given typos (or all
+// pending suggestions, if no typos are given) to the user's corrections file.
+func AcceptSuggestions(typos ...string) error {
+	learnedMu.Lock()
+	defer learnedMu.Unlock()
+	list, err := loadLearnedList()
+	if err != nil {
+		return err
+	}
+	accepted := make(map[string]string)
+	for _, typo := range selectPending(list, typos) {
+		accepted[typo] = list.Pending[typo]
+		delete(list.Pending, typo)
+	}
+	if len(accepted) == 0 {
+		return nil
+	}
+	if err := setUserCorrections(accepted); err != nil {
+		return err
+	}
+	return list.save()
+}
+
+// DismissSuggestions removes the pending suggestions for the given typos (or
+// all pending suggestions, if no typos are given). Dismissed typos will not be
+// suggested again.
+func DismissSuggestions(typos ...string) error {
+	learnedMu.Lock()
+	defer learnedMu.Unlock()
+	list, err := loadLearnedList()
+	if err != nil {
+		return err
+	}
+	for _, typo := range selectPending(list, typos) {
+		delete(list.Pending, typo)
+		list.Dismissed = append(list.Dismissed, typo)
+	}
+	return list.save()
+}
+
+func selectPending(list *learnedList, typos []string) []string {
+	if len(typos) > 0 {
+		var selected []string
+		for _, typo := range typos {
+			if _, ok := list.Pending[typo]; ok {
+				selected = append(selected, typo)
+			}
+		}
+		return selected
+	}
+	selected := make([]string, 0, len(list.Pending))
+	for typo := range list.Pending {
+		selected = append(selected, typo)
+	}
+	return selected
+}
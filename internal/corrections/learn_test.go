@@ -0,0 +1,41 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import "testing"
+
+func TestLearn(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.toml": "teh = 'the'\n"})
+	c, err := NewCorrections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Learn("teh", "the"); ok {
+		t.Error("learnt a typo that is already corrected")
+	}
+	if _, ok := c.Learn("cat", "dog"); ok {
+		t.Error("learnt a rewording")
+	}
+	for i := 1; i < learnThreshold; i++ {
+		if _, ok := c.Learn("recieve", "receive"); ok {
+			t.Fatalf("suggested after %d retypes", i)
+		}
+	}
+	suggestion, ok := c.Learn("recieve", "receive")
+	if !ok || *suggestion != (Suggestion{Typo: "recieve", Correction: "receive"}) {
+		t.Fatalf("suggestion is %v, %t", suggestion, ok)
+	}
+	if _, ok := c.Learn("recieve", "receive"); ok {
+		t.Error("suggested a pending suggestion again")
+	}
+	suggestions, err := Suggestions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Typo != "recieve" {
+		t.Errorf("pending suggestions are %v", suggestions)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue with an entry in a corrections file.
+type Problem struct {
+	File, Typo, Message string
+}
+
+// Lint checks the given corrections files for entries that are mistakes or
+// can never be used. Entries are checked on their own, and for chains and
+// conflicts once the files have been merged. If a dictionary is given, typos
+// that are dictionary words are also reported.
+func Lint(files []string, dictionary *SpellChecker) ([]Problem, error) {
+	var problems []Problem
+	report := func(file, typo, format string, a ...any) {
+		problems = append(problems, Problem{File: file, Typo: typo, Message: fmt.Sprintf(format, a...)})
+	}
+	merged := newList()
+	definedIn := make(map[string][]string)
+	for _, file := range files {
+		layer, err := loadFile(file)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not load corrections file "+file), err)
+		}
+		for typo, e := range layer.words {
+			// an empty replacement deliberately deletes the entry from a
+			// lower layer, so is not reported as overriding it
+			if e.replacement == "" {
+				continue
+			}
+			definedIn[typo] = append(definedIn[typo], file)
+			if typo == e.replacement {
+				report(file, typo, "correction is the same as the typo")
+			}
+			if problem, ok := untypeable(typo); ok {
+				report(file, typo, "%s, so it can never be typed", problem)
+			}
+			if dictionary != nil && !strings.ContainsRune(typo, ' ') && dictionary.Known(typo) {
+				report(file, typo, "is a dictionary word")
+			}
+		}
+		merged.merge(layer)
+	}
+	for typo, in := range definedIn {
+		if len(in) > 1 {
+			report(in[len(in)-1], typo, "overrides the entry in %s", strings.Join(in[:len(in)-1], ", "))
+		}
+	}
+	// case collisions
+	byLower := make(map[string][]string)
+	for typo, e := range merged.words {
+		if !e.exact {
+			byLower[strings.ToLower(typo)] = append(byLower[strings.ToLower(typo)], typo)
+		}
+	}
+	for _, typos := range byLower {
+		if len(typos) < 2 {
+			continue
+		}
+		sort.Strings(typos)
+		for _, typo := range typos {
+			report(merged.words[typo].file, typo, "differs only in case from %s", strings.Join(others(typos, typo), ", "))
+		}
+	}
+	// chains and cycles
+	for typo, e := range merged.words {
+		next, ok := merged.words[e.replacement]
+		if !ok || e.replacement == typo {
+			continue
+		}
+		if next.replacement == typo {
+			report(e.file, typo, "forms a cycle: %s -> %s -> %s", typo, e.replacement, typo)
+		} else {
+			report(e.file, typo, "correction is itself corrected: %s -> %s -> %s", typo, e.replacement, next.replacement)
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		if problems[i].Typo != problems[j].Typo {
+			return problems[i].Typo < problems[j].Typo
+		}
+		return problems[i].Message < problems[j].Message
+	})
+	return problems, nil
+}
+
+// LayerFiles returns the corrections files that exist, in order of increasing
+// precedence. If languages are given, files for other languages are left
+// out.
+func LayerFiles(languages ...string) []string {
+	var files []string
+	for _, file := range layerFiles(languages) {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// untypeable reports why a typo can never be matched when typing, if it
+// cannot: words end at punctuation, symbols and whitespace, and phrases are
+// made of words separated by single spaces.
+func untypeable(typo string) (string, bool) {
+	if strings.Join(strings.Fields(typo), " ") != typo {
+		return "contains whitespace other than single spaces between words", true
+	}
+	for _, r := range typo {
+		if r != ' ' && isDelimiter(r) {
+			return fmt.Sprintf("contains %q, which ends a word", r), true
+		}
+	}
+	return "", false
+}
+
+func others(all []string, exclude string) []string {
+	var o []string
+	for _, s := range all {
+		if s != exclude {
+			o = append(o, s)
+		}
+	}
+	return o
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	dic := filepath.Join(dir, "en.dic")
+	if err := os.WriteFile(dic, []byte("2\nform\nthe\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dictionary, err := NewSpellChecker(dic, 2, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		files      []string
+		dictionary *SpellChecker
+		want       []Problem
+	}{
+		{
+			name:  "no problems",
+			files: []string{"teh = 'the'\nadn = 'and'\n"},
+		},
+		{
+			name:  "same as typo",
+			files: []string{"the = 'the'\n"},
+			want:  []Problem{{File: "0.toml", Typo: "the", Message: "correction is the same as the typo"}},
+		},
+		{
+			name:  "untypeable",
+			files: []string{"\"te.h\" = 'the'\n\"could  of\" = 'could have'\n"},
+			want: []Problem{
+				{File: "0.toml", Typo: "could  of", Message: "contains whitespace other than single spaces between words, so it can never be typed"},
+				{File: "0.toml", Typo: "te.h", Message: "contains '.', which ends a word, so it can never be typed"},
+			},
+		},
+		{
+			name:       "dictionary word",
+			files:      []string{"form = 'from'\n\"form of\" = 'from of'\n"},
+			dictionary: dictionary,
+			want:       []Problem{{File: "0.toml", Typo: "form", Message: "is a dictionary word"}},
+		},
+		{
+			name:  "override",
+			files: []string{"teh = 'the'\n", "teh = 'tea'\n"},
+			want:  []Problem{{File: "1.toml", Typo: "teh", Message: "overrides the entry in 0.toml"}},
+		},
+		{
+			// deleting an entry from a lower layer is deliberate
+			name:  "deletion",
+			files: []string{"teh = 'the'\n", "teh = ''\n"},
+		},
+		{
+			name:  "override after deletion",
+			files: []string{"teh = 'the'\n", "teh = ''\n", "teh = 'tea'\n"},
+			want:  []Problem{{File: "2.toml", Typo: "teh", Message: "overrides the entry in 0.toml"}},
+		},
+		{
+			name:  "case collision",
+			files: []string{"teh = 'the'\nTeh = 'The'\n\n[exact]\nTEH = 'THE'\n"},
+			want: []Problem{
+				{File: "0.toml", Typo: "Teh", Message: "differs only in case from teh"},
+				{File: "0.toml", Typo: "teh", Message: "differs only in case from Teh"},
+			},
+		},
+		{
+			name:  "chain",
+			files: []string{"teh = 'hte'\n", "hte = 'the'\n"},
+			want:  []Problem{{File: "0.toml", Typo: "teh", Message: "correction is itself corrected: teh -> hte -> the"}},
+		},
+		{
+			name:  "cycle",
+			files: []string{"teh = 'hte'\nhte = 'teh'\n"},
+			want: []Problem{
+				{File: "0.toml", Typo: "hte", Message: "forms a cycle: hte -> teh -> hte"},
+				{File: "0.toml", Typo: "teh", Message: "forms a cycle: teh -> hte -> teh"},
+			},
+		},
+		{
+			name:  "chain deleted",
+			files: []string{"teh = 'hte'\nhte = 'the'\n", "hte = ''\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var files []string
+			for i, contents := range tt.files {
+				file := filepath.Join(dir, fmt.Sprintf("%d.toml", i))
+				if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, file)
+			}
+			got, err := Lint(files, tt.dictionary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the files are reported by name, for comparison
+			for i := range got {
+				got[i].File = filepath.Base(got[i].File)
+				got[i].Message = strings.ReplaceAll(got[i].Message, dir+string(filepath.Separator), "")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintInvalidFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "corrections.toml")
+	if err := os.WriteFile(file, []byte("teh = \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lint([]string{file}, nil); err == nil {
+		t.Error("no error for invalid file")
+	}
+}
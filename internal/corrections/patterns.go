@@ -0,0 +1,154 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+const patternsSection = "patterns"
+
+// pattern is a regular expression correction. The expression must match the
+// whole word. The replacement can refer to capture groups with $1 or ${name}
+// and change the case of what follows with \L (lower), \U (upper) and \E (end
+// case change).
+type pattern struct {
+	match   string
+	re      *regexp.Regexp
+	replace string
+}
+
+func newPattern(match, replace string) (*pattern, error) {
+	parsed, err := syntax.Parse(match, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	if matchesEmpty(parsed.Simplify()) {
+		return nil, errors.New("pattern can match an empty string")
+	}
+	re, err := regexp.Compile(`^(?:` + match + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return &pattern{
+		match:   match,
+		re:      re,
+		replace: replace,
+	}, nil
+}
+
+// apply returns the replacement for the word if the pattern matches it and
+// the replacement differs from the word.
+func (p *pattern) apply(word string) (string, bool) {
+	match := p.re.FindStringSubmatchIndex(word)
+	if match == nil {
+		return "", false
+	}
+	var result strings.Builder
+	mode := 'E'
+	template := p.replace
+	for {
+		i := strings.IndexByte(template, '\\')
+		if i < 0 || i == len(template)-1 {
+			break
+		}
+		next := template[i+1]
+		if next != 'L' && next != 'U' && next != 'E' {
+			// not a case change, keep the backslash and what follows it
+			// as-is.
+			result.WriteString(changeCase(mode, string(p.re.ExpandString(nil, template[:i+2], word, match))))
+			template = template[i+2:]
+			continue
+		}
+		result.WriteString(changeCase(mode, string(p.re.ExpandString(nil, template[:i], word, match))))
+		mode = rune(next)
+		template = template[i+2:]
+	}
+	result.WriteString(changeCase(mode, string(p.re.ExpandString(nil, template, word, match))))
+	if result.String() == word {
+		return "", false
+	}
+	return result.String(), true
+}
+
+func changeCase(mode rune, s string) string {
+	switch mode {
+	case 'L':
+		return strings.ToLower(s)
+	case 'U':
+		return strings.ToUpper(s)
+	default:
+		return s
+	}
+}
+
+// matchesEmpty reports whether the given regular expression can match an
+// empty string.
+func matchesEmpty(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) == 0
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL, syntax.OpNoMatch:
+		return false
+	case syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpPlus, syntax.OpCapture:
+		return matchesEmpty(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || matchesEmpty(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !matchesEmpty(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if matchesEmpty(sub) {
+				return true
+			}
+		}
+		return false
+	default:
+		// empty matches and zero-width assertions
+		return true
+	}
+}
+
+// parsePatterns converts the patterns section of a decoded corrections file
+// into a list of compiled patterns, preserving their order.
+func parsePatterns(value any) ([]*pattern, error) {
+	tables, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of tables", patternsSection)
+	}
+	patterns := make([]*pattern, 0, len(tables))
+	for i, t := range tables {
+		table, ok := t.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s entry %d must be a table", patternsSection, i+1)
+		}
+		match, ok := table["match"].(string)
+		if !ok || match == "" {
+			return nil, fmt.Errorf("%s entry %d is missing a match", patternsSection, i+1)
+		}
+		replace, ok := table["replace"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s entry %d is missing a replacement", patternsSection, i+1)
+		}
+		p, err := newPattern(match, replace)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("invalid pattern %q", match), err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
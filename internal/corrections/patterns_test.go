@@ -0,0 +1,92 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPatternApply(t *testing.T) {
+	tests := []struct {
+		name, match, replace, word string
+		want                       string
+		wantOK                     bool
+	}{
+		{name: "capture", match: `(\w+)ign`, replace: "${1}ing", word: "sign", want: "sing", wantOK: true},
+		{name: "whole word only", match: `teh`, replace: "the", word: "tehs"},
+		{name: "unchanged", match: `(the)`, replace: "$1", word: "the"},
+		{name: "upper", match: `(\w)(\w*)`, replace: `\U$1\E$2`, word: "paris", want: "Paris", wantOK: true},
+		{name: "lower", match: `([A-Z]+)s`, replace: `\L$1\Es`, word: "URLs", want: "urls", wantOK: true},
+		{name: "upper to end", match: `nasa(\w*)`, replace: `\Unasa$1`, word: "nasas", want: "NASAS", wantOK: true},
+		{name: "other escape kept", match: `a(b)`, replace: `x\n$1`, word: "ab", want: `x\nb`, wantOK: true},
+		{name: "trailing backslash", match: `ab`, replace: `x\`, word: "ab", want: `x\`, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPattern(tt.match, tt.replace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := p.apply(tt.word)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("apply(%q) = %q, %t, want %q, %t", tt.word, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewPatternEmpty(t *testing.T) {
+	for match, wantErr := range map[string]bool{
+		`a*`:          true,
+		`(a|)`:        true,
+		`a?b?`:        true,
+		`^`:           true,
+		`\b`:          true,
+		`(?:ab){0,2}`: true,
+		`a+`:          false,
+		`a*b`:         false,
+		`(a|b)`:       false,
+		`[a-z]{2}`:    false,
+		`.`:           false,
+	} {
+		_, err := newPattern(match, "x")
+		if (err != nil) != wantErr {
+			t.Errorf("newPattern(%q) error = %v, want error %t", match, err, wantErr)
+		}
+	}
+}
+
+func TestMergePatterns(t *testing.T) {
+	base := newList()
+	for _, match := range []string{`ab`, `cd`} {
+		p, err := newPattern(match, "x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		base.patterns = append(base.patterns, p)
+	}
+	overlay := newList()
+	removed, err := newPattern(`ab`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := newPattern(`ef`, "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay.patterns = []*pattern{removed, added}
+
+	base.merge(overlay)
+	var got []string
+	for _, p := range base.patterns {
+		got = append(got, p.match)
+	}
+	// overlay patterns are tried first
+	if want := []string{`ef`, `cd`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged patterns are %v, want %v", got, want)
+	}
+}
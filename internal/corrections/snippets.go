@@ -0,0 +1,171 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	snippetsSection = "snippets"
+
+	defaultDateLayout = "2006-01-02"
+	defaultTimeLayout = "15:04"
+)
+
+// snippet is a text expansion. Its template is split into parts that are
+// either literal text or a placeholder that is filled in when the snippet is
+// expanded.
+type snippet struct {
+	parts []snippetPart
+}
+
+type snippetPart struct {
+	text, placeholder, arg string
+}
+
+// parseSnippet splits a snippet template into its parts. Placeholders are
+// enclosed in braces, with an optional argument after a colon, such as
+// {date:2006-01-02}. A literal brace is written as {{ or }}.
+func parseSnippet(template string) (*snippet, error) {
+	s := &snippet{}
+	var text strings.Builder
+	var cursors int
+	for len(template) > 0 {
+		switch {
+		case strings.HasPrefix(template, "{{"), strings.HasPrefix(template, "}}"):
+			text.WriteByte(template[0])
+			template = template[2:]
+		case template[0] == '{':
+			end := strings.IndexByte(template, '}')
+			if end < 0 {
+				return nil, errors.New("unterminated placeholder")
+			}
+			placeholder, arg, _ := strings.Cut(template[1:end], ":")
+			switch placeholder {
+			case "date", "time", "clipboard":
+			case "env":
+				if arg == "" {
+					return nil, errors.New("env placeholder requires a variable name")
+				}
+			case "cursor":
+				cursors++
+			default:
+				return nil, fmt.Errorf("unknown placeholder %q", placeholder)
+			}
+			if text.Len() > 0 {
+				s.parts = append(s.parts, snippetPart{text: text.String()})
+				text.Reset()
+			}
+			s.parts = append(s.parts, snippetPart{placeholder: placeholder, arg: arg})
+			template = template[end+1:]
+		default:
+			text.WriteByte(template[0])
+			template = template[1:]
+		}
+	}
+	if cursors > 1 {
+		return nil, errors.New("only one cursor placeholder is allowed")
+	}
+	if text.Len() > 0 {
+		s.parts = append(s.parts, snippetPart{text: text.String()})
+	}
+	return s, nil
+}
+
+// expand fills in the placeholders of the snippet. It returns the expanded
+// text and the number of characters after the cursor placeholder, which is
+// how far the caret should be moved back after typing the text.
+func (s *snippet) expand() (string, int) {
+	var text strings.Builder
+	cursor := -1
+	for _, part := range s.parts {
+		switch part.placeholder {
+		case "":
+			text.WriteString(part.text)
+		case "date":
+			text.WriteString(time.Now().Format(layoutOr(part.arg, defaultDateLayout)))
+		case "time":
+			text.WriteString(time.Now().Format(layoutOr(part.arg, defaultTimeLayout)))
+		case "env":
+			text.WriteString(os.Getenv(part.arg))
+		case "clipboard":
+			clipboard, err := readClipboard()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not read clipboard for snippet.")
+			}
+			text.WriteString(clipboard)
+		case "cursor":
+			cursor = text.Len()
+		}
+	}
+	expanded := text.String()
+	if cursor < 0 {
+		return expanded, 0
+	}
+	return expanded, utf8.RuneCountInString(expanded[cursor:])
+}
+
+func layoutOr(layout, fallback string) string {
+	if layout == "" {
+		return fallback
+	}
+	return layout
+}
+
+// readClipboard returns the contents of the clipboard using whichever of the
+// common command-line clipboard tools is available.
+func readClipboard() (string, error) {
+	commands := [][]string{
+		{"xclip", "-selection", "clipboard", "-out"},
+		{"xsel", "--clipboard", "--output"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append([][]string{{"wl-paste", "--no-newline"}}, commands...)
+	}
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(command[0], command[1:]...).Output()
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+	return "", errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
+
+// parseSnippets converts the snippets section of a decoded corrections file
+// into a set of snippets. A trigger with an empty template is kept as a nil
+// snippet so that it can remove a snippet from a lower layer.
+func parseSnippets(value map[string]any) (map[string]*snippet, error) {
+	snippets := make(map[string]*snippet, len(value))
+	for trigger, v := range value {
+		template, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid template for snippet %q", trigger)
+		}
+		if template == "" {
+			snippets[trigger] = nil
+			continue
+		}
+		s, err := parseSnippet(template)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("invalid snippet %q", trigger), err)
+		}
+		snippets[trigger] = s
+	}
+	return snippets, nil
+}
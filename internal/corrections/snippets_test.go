@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import "testing"
+
+func TestSnippetExpand(t *testing.T) {
+	t.Setenv("AUTOCORRECTOR_TEST", "Josh")
+	tests := []struct {
+		name, template string
+		want           string
+		wantOffset     int
+	}{
+		{name: "text", template: "by the way", want: "by the way"},
+		{name: "cursor", template: "TODO({cursor})", want: "TODO()", wantOffset: 1},
+		{name: "cursor at end", template: "Hi {cursor}", want: "Hi "},
+		{name: "cursor at start", template: "{cursor}é!", want: "é!", wantOffset: 2},
+		{name: "env", template: "Regards,\n{env:AUTOCORRECTOR_TEST}", want: "Regards,\nJosh"},
+		{name: "cursor after env", template: "{env:AUTOCORRECTOR_TEST}: {cursor}.", want: "Josh: .", wantOffset: 1},
+		{name: "braces", template: "{{cursor}}", want: "{cursor}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSnippet(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, offset := s.expand()
+			if got != tt.want || offset != tt.wantOffset {
+				t.Errorf("expand() = %q, %d, want %q, %d", got, offset, tt.want, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestParseSnippetErrors(t *testing.T) {
+	for _, template := range []string{
+		"{cursor} and {cursor}",
+		"{unknown}",
+		"{env}",
+		"{date",
+	} {
+		if _, err := parseSnippet(template); err == nil {
+			t.Errorf("parseSnippet(%q) succeeded", template)
+		}
+	}
+}
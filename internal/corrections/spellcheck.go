@@ -0,0 +1,151 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minSpellCheckLength is the shortest word that will be spell checked. Short
+// words have too many close neighbours for a suggestion to be reliable.
+const minSpellCheckLength = 4
+
+// SpellChecker suggests corrections for words that are not in a dictionary
+// word list, based on the Damerau–Levenshtein distance to the known words.
+type SpellChecker struct {
+	// words maps the lower case form of each known word to its dictionary
+	// form.
+	words map[string]string
+	// byLength holds the lower case known words, indexed by their length in
+	// runes.
+	byLength    [][]string
+	maxDistance int
+	confidence  float64
+}
+
+// NewSpellChecker loads the word list at the given path. The word list can
+// either be a plain list of words, one per line, or a hunspell .dic file.
+// Suggestions will be at most maxDistance edits away from the word and only
+// made if they meet the given confidence (0-1).
+func NewSpellChecker(path string, maxDistance int, confidence float64) (*SpellChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := &SpellChecker{
+		words:       make(map[string]string),
+		maxDistance: maxDistance,
+		confidence:  confidence,
+	}
+	scanner := bufio.NewScanner(f)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// a hunspell .dic file starts with a count of words
+		if first {
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+		// strip hunspell affix flags
+		word, _, _ := strings.Cut(line, "/")
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		s.add(word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	log.Info().Str("file", path).Int("words", len(s.words)).Msg("Opened spell check word list.")
+	return s, nil
+}
+
+func (s *SpellChecker) add(word string) {
+	lower := strings.ToLower(word)
+	if _, ok := s.words[lower]; ok {
+		return
+	}
+	s.words[lower] = word
+	n := utf8.RuneCountInString(lower)
+	for len(s.byLength) <= n {
+		s.byLength = append(s.byLength, nil)
+	}
+	s.byLength[n] = append(s.byLength[n], lower)
+}
+
+// Known reports whether the word, ignoring case, is in the word list.
+func (s *SpellChecker) Known(word string) bool {
+	_, ok := s.words[strings.ToLower(word)]
+	return ok
+}
+
+// Suggest returns a correction for a word that is not in the word list. A
+// suggestion is only made when there is a single closest word and the
+// confidence, based on the number of edits relative to the length of the word,
+// meets the threshold. Words containing anything other than letters, and
+// acronyms or mixed case words, are never corrected.
+func (s *SpellChecker) Suggest(word string) (string, bool) {
+	n := utf8.RuneCountInString(word)
+	if n < minSpellCheckLength {
+		return "", false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return "", false
+		}
+	}
+	if c := detectCase(word); c != lowerCase && c != titleCase {
+		return "", false
+	}
+	lower := strings.ToLower(word)
+	if _, ok := s.words[lower]; ok {
+		return "", false
+	}
+	best, bestDistance, ties := "", s.maxDistance+1, 0
+	for length := n - s.maxDistance; length <= n+s.maxDistance; length++ {
+		if length < 0 || length >= len(s.byLength) {
+			continue
+		}
+		// words whose length differs by more than the best distance so far
+		// cannot be any closer
+		if abs(length-n) > bestDistance {
+			continue
+		}
+		for _, candidate := range s.byLength[length] {
+			d := distance(lower, candidate)
+			switch {
+			case d < bestDistance:
+				best, bestDistance, ties = candidate, d, 1
+			case d == bestDistance:
+				ties++
+			}
+		}
+	}
+	if best == "" || ties > 1 {
+		return "", false
+	}
+	if 1-float64(bestDistance)/float64(n) < s.confidence {
+		return "", false
+	}
+	return applyCase(word, s.words[best]), true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
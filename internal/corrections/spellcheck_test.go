@@ -0,0 +1,83 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "the", b: "the", want: 0},
+		{a: "teh", b: "the", want: 1},
+		{a: "ab", b: "ba", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "", b: "abc", want: 3},
+		{a: "café", b: "cafe", want: 1},
+		// the optimal string alignment variant does not edit a substring
+		// more than once
+		{a: "ca", b: "abc", want: 3},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	// a hunspell dictionary, starting with a word count and with affix flags
+	file := filepath.Join(t.TempDir(), "en.dic")
+	err := os.WriteFile(file, []byte("9\nreceive/S\nbelieve\ntheir\nthere\nthe\nbread\nbreak\nParis\nweird/M\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := NewSpellChecker(file, 2, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loose, err := NewSpellChecker(file, 2, 0.6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		checker *SpellChecker
+		word    string
+		want    string
+	}{
+		{name: "transposition", checker: strict, word: "recieve", want: "receive"},
+		{name: "title case", checker: strict, word: "Wierd", want: "Weird"},
+		{name: "dictionary case", checker: strict, word: "paros", want: "Paris"},
+		{name: "short transposition", checker: strict, word: "thier", want: "their"},
+		{name: "upper case", checker: strict, word: "RECIEVE"},
+		{name: "mixed case", checker: strict, word: "reCieve"},
+		{name: "not letters", checker: strict, word: "rec1eve"},
+		{name: "known", checker: strict, word: "there"},
+		{name: "known other case", checker: strict, word: "paris"},
+		{name: "tie", checker: strict, word: "breax"},
+		{name: "too short", checker: loose, word: "teh"},
+		{name: "below confidence", checker: strict, word: "beleev"},
+		{name: "above confidence", checker: loose, word: "beleev", want: "believe"},
+		{name: "too far", checker: loose, word: "bxxxxve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.checker.Suggest(tt.word)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("Suggest(%q) = %q, %t, want %q", tt.word, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// phraseWindow is the number of preceding words, separated by single spaces,
+// that are tried along with a word when looking for a phrase to correct. It
+// matches the keytracker.
+const phraseWindow = 4
+
+// Match is a typo found in some text.
+type Match struct {
+	Typo, Correction string
+	// Offset is the byte offset of the typo in the text.
+	Offset int
+	// Line and Column are the position of the typo, both starting at 1. The
+	// column is counted in characters (Unicode code points), not bytes.
+	Line, Column int
+}
+
+// word is a word in some text, with its position.
+type word struct {
+	text                 string
+	offset, line, column int
+}
+
+// isDelimiter reports whether r ends a word. The same characters end a word
+// when typing.
+func isDelimiter(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r) || unicode.IsControl(r)
+}
+
+// Find returns the typos in the given text that have corrections, in the
+// order they appear. Text is split into words the same way as when typing:
+// words end at any punctuation, symbol or space character, and phrases are
+// formed by words separated by a single space on the same line. Snippets are
+// not expanded.
+func (c *Corrections) Find(text string) []Match {
+	var matches []Match
+	var preceding []word
+	current := word{line: 1, column: 1}
+	line, column := 1, 1
+	for offset := 0; offset <= len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		end := offset == len(text)
+		if end || isDelimiter(r) {
+			if offset > current.offset {
+				current.text = text[current.offset:offset]
+				if m, ok := c.findPhrase(preceding, current); ok {
+					matches = append(matches, m)
+					// a corrected word cannot be part of another phrase
+					preceding = nil
+				} else if r == ' ' {
+					preceding = append(preceding, current)
+					if len(preceding) > phraseWindow {
+						preceding = preceding[1:]
+					}
+				} else {
+					preceding = nil
+				}
+			} else {
+				preceding = nil
+			}
+			if end {
+				break
+			}
+			if r == '\n' {
+				line, column = line+1, 0
+			}
+			current = word{offset: offset + size, line: line, column: column + 1}
+		}
+		offset += size
+		column++
+	}
+	return matches
+}
+
+// findPhrase looks for a correction for the word, trying the longest phrase
+// formed with the preceding words first.
+func (c *Corrections) findPhrase(preceding []word, w word) (Match, bool) {
+	for i := 0; i <= len(preceding); i++ {
+		words := make([]string, 0, len(preceding)-i+1)
+		for _, p := range preceding[i:] {
+			words = append(words, p.text)
+		}
+		phrase := strings.Join(append(words, w.text), " ")
+		if correction, ok := c.CheckWord(phrase); ok {
+			start := w
+			if i < len(preceding) {
+				start = preceding[i]
+			}
+			return Match{
+				Typo:       phrase,
+				Correction: correction,
+				Offset:     start.offset,
+				Line:       start.line,
+				Column:     start.column,
+			}, true
+		}
+	}
+	return Match{}, false
+}
+
+// Fix returns the text with all typos that have corrections replaced, along
+// with the typos that were found.
+func (c *Corrections) Fix(text string) (string, []Match) {
+	matches := c.Find(text)
+	if len(matches) == 0 {
+		return text, nil
+	}
+	var fixed strings.Builder
+	fixed.Grow(len(text))
+	last := 0
+	for _, m := range matches {
+		fixed.WriteString(text[last:m.Offset])
+		fixed.WriteString(m.Correction)
+		last = m.Offset + len(m.Typo)
+	}
+	fixed.WriteString(text[last:])
+	return fixed.String(), matches
+}
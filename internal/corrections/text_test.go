@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	c := loadTestCorrections(t, `
+teh = 'the'
+alot = 'a lot'
+"could of" = "could have"
+"for all intents and porpoises" = "for all intents and purposes"
+`)
+	tests := []struct {
+		name, text string
+		want       []Match
+	}{
+		{
+			name: "word",
+			text: "teh cat",
+			want: []Match{{Typo: "teh", Correction: "the", Offset: 0, Line: 1, Column: 1}},
+		},
+		{
+			name: "multi-line",
+			text: "the cat\nsat on\n  teh mat, teh end",
+			want: []Match{
+				{Typo: "teh", Correction: "the", Offset: 17, Line: 3, Column: 3},
+				{Typo: "teh", Correction: "the", Offset: 26, Line: 3, Column: 12},
+			},
+		},
+		{
+			name: "punctuation",
+			text: "(teh)",
+			want: []Match{{Typo: "teh", Correction: "the", Offset: 1, Line: 1, Column: 2}},
+		},
+		{
+			name: "phrase",
+			text: "we could of gone",
+			want: []Match{{Typo: "could of", Correction: "could have", Offset: 3, Line: 1, Column: 4}},
+		},
+		{
+			name: "longest phrase",
+			text: "For all intents and porpoises",
+			want: []Match{{Typo: "For all intents and porpoises", Correction: "For all intents and purposes", Offset: 0, Line: 1, Column: 1}},
+		},
+		{
+			name: "phrase case",
+			text: "Could of",
+			want: []Match{{Typo: "Could of", Correction: "Could have", Offset: 0, Line: 1, Column: 1}},
+		},
+		{
+			name: "phrase with two spaces",
+			text: "could  of",
+		},
+		{
+			name: "phrase across lines",
+			text: "could\nof",
+		},
+		{
+			name: "phrase after punctuation",
+			text: "could, of",
+		},
+		{
+			name: "corrected word not in phrase",
+			text: "alot of",
+			want: []Match{{Typo: "alot", Correction: "a lot", Offset: 0, Line: 1, Column: 1}},
+		},
+		{
+			name: "multibyte before typo",
+			text: "éé teh",
+			want: []Match{{Typo: "teh", Correction: "the", Offset: 5, Line: 1, Column: 4}},
+		},
+		{
+			name: "non-BMP before typo",
+			text: "😀 teh",
+			want: []Match{{Typo: "teh", Correction: "the", Offset: 5, Line: 1, Column: 3}},
+		},
+		{
+			name: "typo at end",
+			text: "the end\nteh",
+			want: []Match{{Typo: "teh", Correction: "the", Offset: 8, Line: 2, Column: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Find(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFix(t *testing.T) {
+	c := loadTestCorrections(t, `
+teh = 'the'
+"could of" = "could have"
+`)
+	tests := []struct {
+		text, want string
+	}{
+		{text: "Teh cat could of sat on teh mat.", want: "The cat could have sat on the mat."},
+		{text: "éé teh\nCOULD OF", want: "éé the\nCOULD HAVE"},
+		{text: "nothing to fix", want: "nothing to fix"},
+	}
+	for _, tt := range tests {
+		if got, _ := c.Fix(tt.text); got != tt.want {
+			t.Errorf("Fix(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// reloadDelay is how long to wait after the last change to a corrections file
+// before reloading. Editors often write a file in several steps (truncate,
+// write, rename), so this avoids reloading a half-written file.
+const reloadDelay = 500 * time.Millisecond
+
+// Watch monitors the corrections files for changes and reloads the corrections
+// list whenever any of them change. If the changed files cannot be loaded, the
+// existing list is kept. Watching stops when the context is cancelled.
+func (c *Corrections) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the directories rather than the files themselves, so that files
+	// created after startup or replaced by an editor are picked up.
+	for _, dir := range watchDirs() {
+		if err := watcher.Add(dir); err != nil {
+			log.Debug().Err(err).Str("directory", dir).Msg("Not watching directory for corrections changes.")
+		}
+	}
+	go func() {
+		defer watcher.Close()
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				log.Debug().Msg("Stopping corrections watcher.")
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) && filepath.Base(event.Name) == dropInDirname {
+					if err := watcher.Add(event.Name); err != nil {
+						log.Warn().Err(err).Str("directory", event.Name).Msg("Could not watch drop-in directory.")
+					}
+				}
+				if event.Name == ignoreFile() {
+					c.reloadIgnored()
+					continue
+				}
+				if isCorrectionsFile(event.Name) || filepath.Base(event.Name) == dropInDirname {
+					reload = time.After(reloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warn().Err(err).Msg("Error watching corrections files.")
+			case <-reload:
+				reload = nil
+				c.reload()
+			}
+		}
+	}()
+	return nil
+}
+
+// reload re-reads all corrections files and, if they could all be loaded,
+// replaces the current corrections list with the new one. The lists kept for
+// other languages are discarded.
+func (c *Corrections) reload() {
+	c.mu.Lock()
+	c.loaded = make(map[string]*list)
+	languages := c.languages
+	c.mu.Unlock()
+	if err := c.load(languages); err != nil {
+		log.Warn().Err(err).Msg("Could not reload corrections, keeping existing corrections.")
+		return
+	}
+	c.reloaded()
+}
+
+// OnReload registers fn to be called whenever Watch reloads the corrections
+// list or the ignore list, such as to check text again with the new lists.
+func (c *Corrections) OnReload(fn func()) {
+	c.mu.Lock()
+	c.onReload = append(c.onReload, fn)
+	c.mu.Unlock()
+}
+
+func (c *Corrections) reloaded() {
+	c.mu.Lock()
+	onReload := c.onReload
+	c.mu.Unlock()
+	for _, fn := range onReload {
+		fn()
+	}
+}
+
+// load reads the corrections files for the given languages and, if they could
+// all be loaded, makes them the current languages and replaces the current
+// corrections list with the new one.
+func (c *Corrections) load(languages []string) error {
+	correctionsList, err := loadLanguages(c.layerFiles(languages), languages)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.languages = languages
+	c.correctionsList = correctionsList
+	c.loaded[strings.Join(languages, ",")] = correctionsList
+	c.mu.Unlock()
+	log.Info().
+		Int("entries", len(correctionsList.words)).
+		Int("patterns", len(correctionsList.patterns)).
+		Int("snippets", len(correctionsList.snippets)).
+		Msg("Reloaded corrections.")
+	return nil
+}
+
+// reloadIgnored re-reads the ignore list, which may have been changed by
+// another autocorrector process.
+func (c *Corrections) reloadIgnored() {
+	list, err := loadIgnoreList()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not reload ignore list, keeping existing list.")
+		return
+	}
+	c.mu.Lock()
+	c.ignored = list.words()
+	c.mu.Unlock()
+	log.Debug().Int("words", len(list.Ignore)).Msg("Reloaded ignore list.")
+	c.reloaded()
+}
+
+func watchDirs() []string {
+	return []string{
+		systemPath,
+		filepath.Join(systemPath, dropInDirname),
+		userPath,
+		filepath.Join(userPath, dropInDirname),
+	}
+}
+
+func isCorrectionsFile(path string) bool {
+	if !isCorrectionsFormat(path) {
+		return false
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if lang := fileLanguage(path); lang != "" {
+		name = strings.TrimSuffix(name, "."+lang)
+	}
+	if name == correctionsBasename {
+		return true
+	}
+	return filepath.Base(filepath.Dir(path)) == dropInDirname
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package corrections
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRenamed replaces file by renaming a new file over it, as editors do, so
+// that the watcher sees a single change.
+func writeRenamed(t *testing.T, file, contents string) {
+	t.Helper()
+	tmp := filepath.Join(t.TempDir(), filepath.Base(file))
+	if err := os.WriteFile(tmp, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchOnReload(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.toml": "teh = \"the\"\n"})
+	c, err := NewCorrections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := make(chan struct{}, 10)
+	c.OnReload(func() { reloaded <- struct{}{} })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := c.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	writeRenamed(t, filepath.Join(userPath, "corrections.toml"), "teh = \"the\"\nadn = \"and\"\n")
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reloaded")
+	}
+	if got, ok := c.CheckWord("adn"); !ok || got != "and" {
+		t.Errorf("CheckWord(adn) = %q, %t after reload, want and", got, ok)
+	}
+
+	// the ignore list is also watched
+	writeRenamed(t, ignoreFile(), "ignore = [\"adn\"]\n")
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reloaded")
+	}
+	if got, ok := c.CheckWord("adn"); ok {
+		t.Errorf("CheckWord(adn) = %q after ignoring it", got)
+	}
+}
+
+func TestReloadInvalid(t *testing.T) {
+	useTestLayers(t, nil, map[string]string{"corrections.toml": "teh = \"the\"\n"})
+	c, err := NewCorrections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := false
+	c.OnReload(func() { reloaded = true })
+	file := filepath.Join(userPath, "corrections.toml")
+	if err := os.WriteFile(file, []byte("teh = \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c.reload()
+	// a file that cannot be loaded keeps the existing corrections, without
+	// calling the reload functions
+	if reloaded {
+		t.Error("reload functions called for invalid file")
+	}
+	if _, ok := c.CheckWord("teh"); !ok {
+		t.Error("corrections lost after invalid file")
+	}
+}
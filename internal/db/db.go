@@ -40,6 +40,9 @@ type Counters struct {
 	WordsCorrected   Counter
 	KeysPressed      Counter
 	BackspacePressed Counter
+	SpellChecked     Counter
+	SpellCorrected   Counter
+	SpellReverted    Counter
 }
 
 func (c *Counters) Efficiency() float64 {
@@ -53,6 +56,15 @@ func (c *Counters) Accuracy() float64 {
 	return (1 - float64(c.WordsCorrected.Get())/float64(c.WordsChecked.Get())) * 100
 }
 
+// SpellAccuracy is the percentage of spell check corrections that were not
+// reverted by the user.
+func (c *Counters) SpellAccuracy() float64 {
+	if c.SpellCorrected.Get() == 0 {
+		return 0
+	}
+	return (1 - float64(c.SpellReverted.Get())/float64(c.SpellCorrected.Get())) * 100
+}
+
 func (c *Counters) write(file string) error {
 	fs, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0640)
 	if err != nil {
@@ -106,6 +118,18 @@ func (s *Stats) IncBackspaceCounter() {
 	s.counters.BackspacePressed.Inc()
 }
 
+func (s *Stats) IncSpellCheckedCounter() {
+	s.counters.SpellChecked.Inc()
+}
+
+func (s *Stats) IncSpellCorrectedCounter() {
+	s.counters.SpellCorrected.Inc()
+}
+
+func (s *Stats) IncSpellRevertedCounter() {
+	s.counters.SpellReverted.Inc()
+}
+
 func (s *Stats) GetCheckedTotal() uint64 {
 	return s.counters.WordsChecked.Get()
 }
@@ -122,6 +146,22 @@ func (s *Stats) GetBackspacePressed() uint64 {
 	return s.counters.BackspacePressed.Get()
 }
 
+func (s *Stats) GetSpellCheckedTotal() uint64 {
+	return s.counters.SpellChecked.Get()
+}
+
+func (s *Stats) GetSpellCorrectedTotal() uint64 {
+	return s.counters.SpellCorrected.Get()
+}
+
+func (s *Stats) GetSpellRevertedTotal() uint64 {
+	return s.counters.SpellReverted.Get()
+}
+
+func (s *Stats) GetSpellAccuracy() float64 {
+	return s.counters.SpellAccuracy()
+}
+
 func (s *Stats) GetAccuracy() float64 {
 	return s.counters.Accuracy()
 }
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package focus finds the application window that currently has the keyboard
+// focus.
+package focus
+
+import (
+	"strings"
+	"sync"
+)
+
+// Window is the focused application window.
+type Window struct {
+	// Class is the window's class (on X11) or app ID (on Wayland), which
+	// names the application, such as firefox or kitty.
+	Class string
+	// Instance is the instance name from the window's class, if known, which
+	// some applications set to something more specific than the class.
+	Instance string
+	Title    string
+}
+
+// Is reports whether the window belongs to the named application, by
+// comparing the name to the window's class and instance, ignoring case.
+func (w Window) Is(app string) bool {
+	return app != "" && (strings.EqualFold(app, w.Class) || strings.EqualFold(app, w.Instance))
+}
+
+// Provider finds the focused window.
+type Provider interface {
+	Focused() (Window, error)
+}
+
+// Static is a Provider that reports the window it was last given, for use
+// when the focused window cannot be found from the system, such as in tests.
+type Static struct {
+	window Window
+	mu     sync.Mutex
+}
+
+// NewStatic creates a Static provider that reports the given window as
+// focused.
+func NewStatic(w Window) *Static {
+	return &Static{window: w}
+}
+
+// Set changes the window reported as focused.
+func (s *Static) Set(w Window) {
+	s.mu.Lock()
+	s.window = w
+	s.mu.Unlock()
+}
+
+func (s *Static) Focused() (Window, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.window, nil
+}
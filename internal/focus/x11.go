@@ -0,0 +1,77 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package focus
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// activeWindowRe matches the window ID in the _NET_ACTIVE_WINDOW property
+	// of the root window.
+	activeWindowRe = regexp.MustCompile(`window id # (0x[0-9a-fA-F]+)`)
+	// quotedRe matches each quoted string in a property value.
+	quotedRe = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+)
+
+// X11 finds the focused window from the _NET_ACTIVE_WINDOW property set by
+// the window manager, using xprop. It also works for applications running
+// under XWayland.
+type X11 struct{}
+
+// NewX11 creates a Provider for X11 desktops.
+func NewX11() *X11 {
+	return &X11{}
+}
+
+func (x *X11) Focused() (Window, error) {
+	out, err := exec.Command("xprop", "-root", "_NET_ACTIVE_WINDOW").Output()
+	if err != nil {
+		return Window{}, errors.Join(errors.New("could not find active window"), err)
+	}
+	m := activeWindowRe.FindSubmatch(out)
+	if m == nil || string(m[1]) == "0x0" {
+		// no window has focus, such as when the desktop is focused
+		return Window{}, nil
+	}
+	out, err = exec.Command("xprop", "-id", string(m[1]), "WM_CLASS", "_NET_WM_NAME").Output()
+	if err != nil {
+		return Window{}, errors.Join(errors.New("could not read active window properties"), err)
+	}
+	return parseWindowProperties(string(out)), nil
+}
+
+// parseWindowProperties reads the window class and title from the output of
+// xprop, such as:
+//
+//	WM_CLASS(STRING) = "Navigator", "firefox"
+//	_NET_WM_NAME(UTF8_STRING) = "Mozilla Firefox"
+func parseWindowProperties(out string) Window {
+	var w Window
+	for _, line := range strings.Split(out, "\n") {
+		name, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		var values []string
+		for _, quoted := range quotedRe.FindAllString(value, -1) {
+			if s, err := strconv.Unquote(quoted); err == nil {
+				values = append(values, s)
+			}
+		}
+		switch {
+		case strings.HasPrefix(name, "WM_CLASS") && len(values) == 2:
+			w.Instance, w.Class = values[0], values[1]
+		case strings.HasPrefix(name, "_NET_WM_NAME") && len(values) == 1:
+			w.Title = values[0]
+		}
+	}
+	return w
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"context"
+	"strings"
+
+	kbd "github.com/joshuar/gokbd"
+)
+
+// keyLeft is the Linux input event code for the left arrow key.
+const keyLeft = 105
+
+// KeyState is whether a key event is a press, release or auto-repeat.
+type KeyState int
+
+const (
+	KeyPress KeyState = iota
+	KeyRelease
+	KeyHold
+)
+
+// KeyEvent is a single key event from an Input.
+type KeyEvent struct {
+	// Rune is the character the key represents, if any.
+	Rune rune
+	// Backspace is true for the backspace key.
+	Backspace bool
+	// Name is the Linux name of the key without the KEY_ prefix, such as A
+	// or LEFTCTRL, if known. It is used to detect shortcuts.
+	Name  string
+	State KeyState
+}
+
+// Input is a source of key events, such as the keyboards attached to the
+// system. The events channel is closed when there are no more events.
+type Input interface {
+	Events() <-chan KeyEvent
+}
+
+// Output is where corrections are typed, such as a virtual keyboard.
+type Output interface {
+	// TypeBackspace erases the character before the caret.
+	TypeBackspace()
+	// TypeString types out the given text.
+	TypeString(s string)
+	// TypeLeft moves the caret one character to the left.
+	TypeLeft()
+	// Close releases the output once the keytracker has stopped.
+	Close()
+}
+
+// keyboardInput is an Input for all of the keyboards attached to the system.
+type keyboardInput struct {
+	events chan KeyEvent
+}
+
+func (i *keyboardInput) Events() <-chan KeyEvent {
+	return i.events
+}
+
+// NewKeyboardInput returns an Input that reads key events from all keyboards
+// attached to the system, until the context is cancelled.
+func NewKeyboardInput(ctx context.Context) Input {
+	input := &keyboardInput{
+		events: make(chan KeyEvent),
+	}
+	kbdEvents := kbd.SnoopAllKeyboards(ctx, kbd.OpenAllKeyboardDevices())
+	go func() {
+		defer close(input.events)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case k, ok := <-kbdEvents:
+				if !ok {
+					return
+				}
+				event := KeyEvent{
+					Rune:      k.AsRune,
+					Backspace: k.IsBackspace(),
+					Name:      strings.TrimPrefix(k.EventName, "KEY_"),
+				}
+				switch {
+				case k.IsKeyPress():
+					event.State = KeyPress
+				case k.IsKeyRelease():
+					event.State = KeyRelease
+				default:
+					event.State = KeyHold
+				}
+				select {
+				case input.events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return input
+}
+
+// virtualKeyboard is an Output that types using a virtual (uinput) keyboard.
+type virtualKeyboard struct {
+	*kbd.VirtualKeyboardDevice
+}
+
+func (v *virtualKeyboard) TypeLeft() {
+	v.TypeKey(keyLeft, false)
+}
+
+// NewVirtualKeyboard returns an Output that types through a new virtual
+// keyboard device.
+func NewVirtualKeyboard() (Output, error) {
+	vKbd, err := kbd.NewVirtualKeyboard("autocorrector")
+	if err != nil {
+		return nil, err
+	}
+	return &virtualKeyboard{VirtualKeyboardDevice: vKbd}, nil
+}
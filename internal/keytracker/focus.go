@@ -0,0 +1,156 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/focus"
+	"github.com/rs/zerolog/log"
+)
+
+// focusPollInterval is how often the focused window is checked.
+const focusPollInterval = 500 * time.Millisecond
+
+// focusTracker holds what is known about the focused window. The window is
+// checked both periodically and before anything is done with a typed word,
+// as a window where secrets are typed may have been focused since the last
+// check.
+type focusTracker struct {
+	provider focus.Provider
+	cfg      *config.Config
+	secure   *secureMatcher
+	mu       sync.Mutex
+	last     focus.Window
+	checked  bool
+	// unavailable is set once the focused window cannot be found at all.
+	unavailable bool
+}
+
+func newFocusTracker(provider focus.Provider, cfg *config.Config) *focusTracker {
+	return &focusTracker{
+		provider: provider,
+		cfg:      cfg,
+		secure:   newSecureMatcher(cfg.SecureInput),
+	}
+}
+
+// trackFocus periodically checks the focused window, until the context is
+// cancelled, the keytracker stops or the focused window cannot be found at
+// all.
+func (kt *KeyTracker) trackFocus(ctx context.Context) {
+	ticker := time.NewTicker(focusPollInterval)
+	defer ticker.Stop()
+	for {
+		err := kt.checkFocus()
+		switch {
+		case errors.Is(err, exec.ErrNotFound):
+			log.Warn().Err(err).Msg("Cannot find the focused window, application profiles and secure input detection disabled.")
+			return
+		case err != nil:
+			log.Debug().Err(err).Msg("Could not find focused window.")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-kt.Done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkFocus finds the focused window and, if it has changed, sets the
+// application for corrections limited to certain applications, applies the
+// profile for the application, if there is one, and pauses while secrets may
+// be typed in the window.
+func (kt *KeyTracker) checkFocus() error {
+	f := kt.focus
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.unavailable {
+		return nil
+	}
+	w, err := f.provider.Focused()
+	if errors.Is(err, exec.ErrNotFound) {
+		f.unavailable = true
+	}
+	if err != nil || (f.checked && w == f.last) {
+		return err
+	}
+	// the title is checked too, as a terminal only shows it is prompting for
+	// a password in its title
+	kt.setSecure(f.secure.matches(w))
+	if !f.checked || w.Class != f.last.Class || w.Instance != f.last.Instance {
+		kt.applyProfile(w, f.cfg)
+	}
+	f.checked = true
+	f.last = w
+	return nil
+}
+
+// ignoringInput checks the focused window again and reports whether typed
+// words should be ignored, because secrets may be typed in it or corrections
+// are off in it.
+func (kt *KeyTracker) ignoringInput() bool {
+	if err := kt.checkFocus(); err != nil {
+		log.Debug().Err(err).Msg("Could not find focused window.")
+	}
+	return kt.secure.Load() || kt.disabled.Load()
+}
+
+// applyProfile sets up the keytracker for a newly focused window.
+func (kt *KeyTracker) applyProfile(w focus.Window, cfg *config.Config) {
+	kt.corrections.SetApplication(w.Class, w.Instance)
+	name, profile, ok := cfg.Profile(w.Is)
+	if !ok {
+		log.Debug().Str("class", w.Class).Msg("Focused application has no profile.")
+		kt.disabled.Store(false)
+		kt.setProfileLanguages(nil)
+		return
+	}
+	log.Debug().Str("class", w.Class).Str("profile", name).
+		Bool("enabled", profile.CorrectionsEnabled()).
+		Msg("Using profile for focused application.")
+	kt.disabled.Store(!profile.CorrectionsEnabled())
+	kt.setProfileLanguages(profile.Languages)
+}
+
+// setLanguages changes the languages whose corrections are used, unless the
+// profile for the focused application chooses its own.
+func (kt *KeyTracker) setLanguages(languages []string) {
+	kt.mu.Lock()
+	kt.languages = languages
+	kt.mu.Unlock()
+	kt.updateLanguages()
+}
+
+// setProfileLanguages sets the languages chosen by the profile for the focused
+// application, if any.
+func (kt *KeyTracker) setProfileLanguages(languages []string) {
+	kt.mu.Lock()
+	kt.profileLanguages = languages
+	kt.mu.Unlock()
+	kt.updateLanguages()
+}
+
+func (kt *KeyTracker) updateLanguages() {
+	kt.mu.Lock()
+	languages := kt.languages
+	if len(kt.profileLanguages) > 0 {
+		languages = kt.profileLanguages
+	}
+	kt.mu.Unlock()
+	kt.corrections.SetLanguages(languages...)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/focus"
+)
+
+// waitFor waits for the condition to become true.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Profiles: map[string]config.Profile{
+			"terminals": {Apps: []string{"kitty"}, Enabled: &disabled},
+			"german":    {Apps: []string{"libreoffice-writer"}, Languages: []string{"de"}},
+		},
+	}
+	window := focus.NewStatic(focus.Window{Class: "firefox", Instance: "Navigator"})
+	kt, input, output, agent := startTestKeyTracker(t, map[string]string{
+		"corrections.en.toml": "teh = 'the'\n",
+		"corrections.de.toml": "nciht = 'nicht'\n",
+	}, window, cfg)
+	corrected := func() {
+		t.Helper()
+		select {
+		case <-agent.notifications:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no correction made")
+		}
+	}
+
+	// no profile, so the defaults apply
+	typeKeys(input, "teh ")
+	corrected()
+
+	window.Set(focus.Window{Class: "kitty", Instance: "kitty"})
+	waitFor(t, "profile to disable corrections", kt.disabled.Load)
+	typeKeys(input, "teh ")
+	flushKeys(input)
+
+	// the profile is found from the instance as well as the class
+	window.Set(focus.Window{Class: "libreoffice", Instance: "libreoffice-writer"})
+	waitFor(t, "profile languages", func() bool {
+		return reflect.DeepEqual(kt.corrections.ActiveLanguages(), []string{"de"})
+	})
+	if kt.disabled.Load() {
+		t.Error("corrections still disabled")
+	}
+	typeKeys(input, "teh nciht ")
+	corrected()
+
+	window.Set(focus.Window{Class: "firefox", Instance: "Navigator"})
+	waitFor(t, "default languages", func() bool {
+		return len(kt.corrections.ActiveLanguages()) == 0
+	})
+	typeKeys(input, "teh ")
+	waitDone(t, kt, input)
+
+	if got, want := output.Typed(), "\b\b\b\bthe \b\b\b\b\b\bnicht \b\b\b\bthe "; got != want {
+		t.Errorf("typed %q, want %q", got, want)
+	}
+}
+
+func TestSecureMatcher(t *testing.T) {
+	cfg, err := config.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secure := newSecureMatcher(cfg.SecureInput)
+	tests := []struct {
+		window focus.Window
+		want   bool
+	}{
+		{window: focus.Window{Class: "KeePassXC", Title: "Passwords.kdbx"}, want: true},
+		{window: focus.Window{Class: "keepassxc"}, want: true},
+		{window: focus.Window{Class: "kitty", Title: "[sudo] password for josh"}, want: true},
+		{window: focus.Window{Class: "kitty", Title: "Enter passphrase for key"}, want: true},
+		{window: focus.Window{Class: "Gcr-prompter", Title: "Unlock"}, want: true},
+		{window: focus.Window{Class: "kitty", Title: "Authentication Required"}, want: true},
+		{window: focus.Window{Class: "kitty", Title: "~/src/sudoku"}, want: false},
+		{window: focus.Window{Class: "firefox", Title: "Passwords - Mozilla Firefox"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := secure.matches(tt.window); got != tt.want {
+			t.Errorf("%+v is secure = %t, want %t", tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestSecureInput(t *testing.T) {
+	cfg, err := config.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := focus.NewStatic(focus.Window{Class: "firefox", Instance: "Navigator"})
+	kt, input, output, agent := startTestKeyTracker(t, map[string]string{
+		"corrections.toml": "teh = 'the'\n",
+	}, window, cfg)
+
+	typeKeys(input, "teh ")
+	select {
+	case <-agent.notifications:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no correction made")
+	}
+
+	// typed straight away, before the window is next polled
+	window.Set(focus.Window{Class: "kitty", Instance: "kitty", Title: "[sudo] password for josh"})
+	typeKeys(input, "teh ")
+	flushKeys(input)
+	if !kt.secure.Load() {
+		t.Error("secure input not detected")
+	}
+
+	window.Set(focus.Window{Class: "kitty", Instance: "kitty", Title: "~"})
+	waitFor(t, "secure input to finish", func() bool { return !kt.secure.Load() })
+	typeKeys(input, "teh ")
+	waitDone(t, kt, input)
+
+	if got, want := output.Typed(), "\b\b\b\bthe \b\b\b\bthe "; got != want {
+		t.Errorf("typed %q, want %q", got, want)
+	}
+	select {
+	case c := <-agent.notifications:
+		if c.Word != "teh" {
+			t.Errorf("notified of %q", c.Word)
+		}
+	default:
+		t.Error("no notification for the last correction")
+	}
+	select {
+	case c := <-agent.notifications:
+		t.Errorf("notified of %q typed in a secure window", c.Word)
+	default:
+	}
+}
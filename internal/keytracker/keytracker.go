@@ -8,12 +8,15 @@ package keytracker
 import (
 	"bytes"
 	"context"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/joshuar/autocorrector/internal/config"
 	"github.com/joshuar/autocorrector/internal/corrections"
-	kbd "github.com/joshuar/gokbd"
+	"github.com/joshuar/autocorrector/internal/focus"
 	"github.com/rs/zerolog/log"
 )
 
@@ -22,15 +25,42 @@ type stats interface {
 	IncBackspaceCounter()
 	IncCheckedCounter()
 	IncCorrectedCounter()
+	IncSpellCheckedCounter()
+	IncSpellCorrectedCounter()
+	IncSpellRevertedCounter()
 }
 
 type agent interface {
 	NotificationCh() chan *Correction
+	SuggestionCh() chan *corrections.Suggestion
+	Toggle()
 }
 
+// phraseWindow is the number of preceding words tracked for matching
+// multi-word corrections.
+const phraseWindow = 4
+
 type Correction struct {
 	Word, Correction string
 	Punct            rune
+	// Preceding holds the words typed immediately before Word, each separated
+	// by a single space, oldest first. It is used to match phrases.
+	Preceding []string
+	// CursorBack is how many characters to move the caret back after typing
+	// the correction, used by snippets with a cursor placeholder.
+	CursorBack int
+	// SpellChecked is true when the correction was suggested by the spell
+	// checker rather than found in the corrections list.
+	SpellChecked bool
+}
+
+// punct returns the punctuation mark or space that ended the word, or an empty
+// string if the word was corrected before it was finished.
+func (c *Correction) punct() string {
+	if c.Punct == 0 {
+		return ""
+	}
+	return string(c.Punct)
 }
 
 func NewCorrection(word, correction string, punct rune) *Correction {
@@ -45,55 +75,194 @@ func NewCorrection(word, correction string, punct rune) *Correction {
 // indicating when word/line delimiter characters are encountered or
 // backspace is pressed
 type KeyTracker struct {
-	kbd       *kbd.VirtualKeyboardDevice
-	kbdEvents <-chan kbd.KeyEvent
-	paused    bool
-	ToggleCh  chan bool
+	input    Input
+	output   Output
+	paused   bool
+	ToggleCh chan bool
+	// LanguageCh changes the languages whose corrections are used.
+	LanguageCh chan []string
+	// Done is closed once the keytracker has stopped, either because the
+	// context was cancelled or the input has no more events.
+	Done chan struct{}
+	// lastCorrection is the most recent correction, held until the next key
+	// is pressed so that it can be undone.
+	lastCorrection *Correction
+	// rejected holds the words whose corrections have been undone.
+	rejected     map[string]bool
+	corrections  *corrections.Corrections
+	spellChecker *corrections.SpellChecker
+	// dryRun is true when rejected and retyped words should not be saved.
+	dryRun bool
+	// shortcuts are the key chords that control the keytracker.
+	shortcuts config.Shortcuts
+	// disabled is true while the focused application's profile turns
+	// corrections off.
+	disabled atomic.Bool
+	// secure is true while a window where secrets are typed has the focus.
+	// Key presses are ignored and wipeCh is signalled to wipe anything
+	// already typed.
+	secure atomic.Bool
+	wipeCh chan struct{}
+	// focus follows the focused window, if there is a focus provider.
+	focus *focusTracker
+	// languages are the languages whose corrections are used, unless the
+	// profile for the focused application sets profileLanguages.
+	languages        []string
+	profileLanguages []string
+	mu               sync.Mutex
 }
 
-func (kt *KeyTracker) slurpWords(ctx context.Context, wordCh chan *Correction, stats stats) {
+func (kt *KeyTracker) slurpWords(ctx context.Context, wordCh chan *Correction, agent agent, stats stats) {
 	charBuf := new(bytes.Buffer)
+	var preceding []string
+	var reverts revertTracker
+	var retypes retypeTracker
+	keys := newShortcuts(kt.shortcuts)
+	// skipWord is set by the skip shortcut to leave the word being typed
+	// uncorrected.
+	skipWord := false
+	// forget wipes anything typed so far, along with what is known about the
+	// preceding words.
+	forget := func() {
+		wipeBuffer(charBuf)
+		skipWord = false
+		preceding = nil
+		reverts.reset()
+		retypes.reset()
+	}
 	log.Debug().Msg("Slurping words...")
 	for {
 		select {
 		case <-ctx.Done():
 			log.Debug().Msg("Stopping slurpWords.")
+			wipeBuffer(charBuf)
 			close(wordCh)
 			return
-		case k := <-kt.kbdEvents:
+		case <-kt.wipeCh:
+			forget()
+		case k, ok := <-kt.input.Events():
+			if !ok {
+				log.Debug().Msg("No more key events, stopping slurpWords.")
+				wipeBuffer(charBuf)
+				close(wordCh)
+				return
+			}
+			// shortcuts are checked first so that corrections can be
+			// turned back on while paused
+			if action, ok := keys.match(k); ok {
+				active := !kt.paused && !kt.secure.Load() && !kt.disabled.Load()
+				switch {
+				case action == toggleShortcut:
+					log.Debug().Msg("Toggling corrections from shortcut.")
+					go agent.Toggle()
+				case action == correctShortcut && active && charBuf.Len() > 0:
+					if kt.ignoringInput() {
+						forget()
+						continue
+					}
+					word := NewCorrection(charBuf.String(), "", 0)
+					word.Preceding = append([]string(nil), preceding...)
+					wordCh <- word
+					wipeBuffer(charBuf)
+					skipWord = false
+					preceding = nil
+				case action == skipShortcut && active:
+					log.Debug().Msg("Skipping correction of current word.")
+					skipWord = true
+				}
+				continue
+			}
 			if kt.paused {
 				continue
 			}
-			if k.IsKeyRelease() {
+			if kt.secure.Load() || kt.disabled.Load() {
+				// a secret may be being typed, or corrections are off in
+				// the focused application, so forget anything typed in it
+				forget()
+				continue
+			}
+			if k.State == KeyRelease {
+				if last := kt.takeLastCorrection(); last != nil {
+					if k.Backspace {
+						stats.IncBackspaceCounter()
+						kt.undoCorrection(last, stats)
+						continue
+					}
+					reverts.track(last)
+				}
 				switch {
-				case k.IsBackspace():
+				case k.Backspace:
 					// backspace key
 					stats.IncBackspaceCounter()
+					retypes.backspace(charBuf.String())
 					if charBuf.Len() > 0 {
 						charBuf.Truncate(charBuf.Len() - 1)
+					} else {
+						// deleting into the previous word, so the preceding
+						// words no longer reflect what is on screen
+						preceding = nil
+						reverts.erase()
 					}
-				case k.AsRune == '\n' || unicode.IsControl(k.AsRune):
+				case k.Rune == '\n' || unicode.IsControl(k.Rune):
 					stats.IncKeyCounter()
 					// newline or control character, reset the buffer
-					charBuf.Reset()
-				case unicode.IsPunct(k.AsRune), unicode.IsSymbol(k.AsRune), unicode.IsSpace(k.AsRune):
+					wipeBuffer(charBuf)
+					skipWord = false
+					preceding = nil
+					reverts.reset()
+					retypes.reset()
+				case unicode.IsPunct(k.Rune), unicode.IsSymbol(k.Rune), unicode.IsSpace(k.Rune):
 					stats.IncKeyCounter()
 					// a punctuation mark, which would indicate a word has been typed, so handle that
 					//
 					// most other punctuation should indicate end of word, so
 					// handle that
 					if charBuf.Len() > 0 {
-						wordCh <- NewCorrection(charBuf.String(), "", k.AsRune)
-						charBuf.Reset()
+						// the word may have been typed into a window for
+						// secrets focused since the last check, so check
+						// again before it is corrected, learnt or rejected
+						if kt.ignoringInput() {
+							forget()
+							continue
+						}
+						word := NewCorrection(charBuf.String(), "", k.Rune)
+						word.Preceding = append([]string(nil), preceding...)
+						if reverted := reverts.reverted(word.Word); reverted != nil {
+							kt.reject(reverted, stats)
+						}
+						if typo, ok := retypes.word(word.Word); ok {
+							go kt.learn(typo, word.Word, agent)
+						}
+						skipped := skipWord
+						if skipWord {
+							skipWord = false
+						} else {
+							wordCh <- word
+						}
+						// only words separated by a single space can form a
+						// phrase, and a skipped word is left alone
+						if k.Rune == ' ' && !skipped {
+							preceding = append(preceding, charBuf.String())
+							if len(preceding) > phraseWindow {
+								preceding = preceding[1:]
+							}
+						} else {
+							preceding = nil
+						}
+						wipeBuffer(charBuf)
+					} else {
+						preceding = nil
+						retypes.key()
 					}
 				default:
 					stats.IncKeyCounter()
-					// case unicode.IsDigit(k.AsRune), unicode.IsLetter(k.AsRune):
+					retypes.key()
+					// case unicode.IsDigit(k.Rune), unicode.IsLetter(k.Rune):
 					// a letter or number
-					_, err := charBuf.WriteRune(k.AsRune)
+					_, err := charBuf.WriteRune(k.Rune)
 					if err != nil {
 						log.Debug().Caller().Err(err).
-							Msgf("Failed to write %v to character buffer.", k.AsRune)
+							Msgf("Failed to write %v to character buffer.", k.Rune)
 					}
 				}
 			}
@@ -101,22 +270,77 @@ func (kt *KeyTracker) slurpWords(ctx context.Context, wordCh chan *Correction, s
 	}
 }
 
-func (kt *KeyTracker) checkWord(ctx context.Context, wordCh chan *Correction, correctionCh chan *Correction, corrections *corrections.Corrections, stats stats) {
+func (kt *KeyTracker) checkWord(ctx context.Context, wordCh chan *Correction, correctionCh chan *Correction, stats stats) {
+	// sinceCorrection is the number of words checked since the last
+	// correction. Words before a correction are no longer on screen as they
+	// were typed, so they cannot be part of a phrase.
+	sinceCorrection := phraseWindow
 	for {
 		select {
 		case <-ctx.Done():
 			log.Debug().Msg("Stopping checkWord.")
 			close(correctionCh)
 			return
-		case w := <-wordCh:
+		case w, ok := <-wordCh:
+			if !ok {
+				close(correctionCh)
+				return
+			}
 			log.Debug().Msgf("Checking word: %s", w.Word)
 			stats.IncCheckedCounter()
-			var ok bool
-			if w.Correction, ok = corrections.CheckWord(w.Word); ok {
+			if len(w.Preceding) > sinceCorrection {
+				w.Preceding = w.Preceding[len(w.Preceding)-sinceCorrection:]
+			}
+			if sinceCorrection < phraseWindow {
+				sinceCorrection++
+			}
+			if expansion, offset, ok := kt.corrections.CheckSnippet(w.Word); ok && !kt.isRejected(w.Word) {
+				w.Correction = expansion
+				w.CursorBack = offset
+				sinceCorrection = 0
 				correctionCh <- w
+				continue
 			}
+			if kt.checkPhrase(w) || kt.spellCheck(w, stats) {
+				sinceCorrection = 0
+				correctionCh <- w
+			}
+		}
+	}
+}
+
+// checkPhrase looks for a correction for the word, trying the longest phrase
+// formed with the preceding words first. If a phrase matches, the word is
+// replaced with the full phrase so that all of it is erased when corrected.
+func (kt *KeyTracker) checkPhrase(w *Correction) bool {
+	for i := 0; i <= len(w.Preceding); i++ {
+		phrase := strings.Join(append(w.Preceding[i:len(w.Preceding):len(w.Preceding)], w.Word), " ")
+		if kt.isRejected(phrase) {
+			continue
+		}
+		if correction, ok := kt.corrections.CheckWord(phrase); ok {
+			w.Word = phrase
+			w.Correction = correction
+			return true
 		}
 	}
+	return false
+}
+
+// spellCheck asks the spell checker, if enabled, for a correction for a word
+// that is not in the corrections list.
+func (kt *KeyTracker) spellCheck(w *Correction, stats stats) bool {
+	if kt.spellChecker == nil || kt.isRejected(w.Word) || kt.corrections.IsIgnored(w.Word) {
+		return false
+	}
+	stats.IncSpellCheckedCounter()
+	correction, ok := kt.spellChecker.Suggest(w.Word)
+	if !ok {
+		return false
+	}
+	w.Correction = correction
+	w.SpellChecked = true
+	return true
 }
 
 func (kt *KeyTracker) correctWord(ctx context.Context, correctionCh chan *Correction, agent agent, stats stats) {
@@ -125,20 +349,36 @@ func (kt *KeyTracker) correctWord(ctx context.Context, correctionCh chan *Correc
 		case <-ctx.Done():
 			log.Debug().Msg("Stopping correctWord.")
 			return
-		case correction := <-correctionCh:
+		case correction, ok := <-correctionCh:
+			if !ok {
+				return
+			}
 			if !kt.paused {
 				log.Debug().Msgf("Making correction %s to %s", correction.Word, correction.Correction)
 
 				// Erase the existing word.
 				// Effectively, hit backspace key for the length of the word plus the punctuation mark.
-				for i := 0; i <= utf8.RuneCountInString(correction.Word); i++ {
-					kt.kbd.TypeBackspace()
+				for i := 0; i < utf8.RuneCountInString(correction.Word+correction.punct()); i++ {
+					kt.output.TypeBackspace()
 				}
 				// Insert the replacement.
 				// Type out the replacement and whatever punctuation/delimiter was after it.
-				kt.kbd.TypeString(correction.Correction + string(correction.Punct))
+				kt.output.TypeString(correction.Correction + correction.punct())
+				// Move the caret back to the cursor position of a snippet,
+				// which is before the punctuation mark as well.
+				if correction.CursorBack > 0 {
+					for i := 0; i < correction.CursorBack+utf8.RuneCountInString(correction.punct()); i++ {
+						kt.output.TypeLeft()
+					}
+				} else {
+					kt.setLastCorrection(correction)
+				}
+			}
+			if correction.SpellChecked {
+				stats.IncSpellCorrectedCounter()
+			} else {
+				stats.IncCorrectedCounter()
 			}
-			stats.IncCorrectedCounter()
 			agent.NotificationCh() <- correction
 		}
 	}
@@ -149,32 +389,92 @@ func (kt *KeyTracker) controlKeyTracker(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			log.Debug().Msg("Stopping keytracker.")
-			kt.kbd.Close()
+			return
+		case <-kt.Done:
 			return
 		case v := <-kt.ToggleCh:
 			kt.paused = v
 			log.Debug().Msgf("Keytracker paused: %t", kt.paused)
+		case languages := <-kt.LanguageCh:
+			kt.setLanguages(languages)
 		}
 	}
 }
 
-// NewKeyTracker creates a new keyTracker struct
-func NewKeyTracker(ctx context.Context, agent agent, stats stats) (*KeyTracker, error) {
-	vKbd, err := kbd.NewVirtualKeyboard("autocorrector")
+// NewKeyTracker creates a new keyTracker struct that watches all keyboards
+// attached to the system, makes corrections with a virtual keyboard and
+// follows the focused X11 window for application profiles.
+func NewKeyTracker(ctx context.Context, cfg *config.Config, agent agent, stats stats) (*KeyTracker, error) {
+	output, err := NewVirtualKeyboard()
 	if err != nil {
 		return nil, err
 	}
-	kt := &KeyTracker{
-		kbd:       vKbd,
-		kbdEvents: kbd.SnoopAllKeyboards(ctx, kbd.OpenAllKeyboardDevices()),
-		paused:    false,
-		ToggleCh:  make(chan bool),
-	}
-	correctionsList, err := corrections.NewCorrections()
+	return NewKeyTrackerWithDevices(ctx, NewKeyboardInput(ctx), output, focus.NewX11(), cfg, agent, stats)
+}
+
+// NewKeyTrackerWithDevices creates a new keyTracker struct that reads key
+// events from the given input and makes corrections through the given output.
+// The output is closed once the keytracker stops. If a focus provider is
+// given, it is used to apply the profile for the focused application.
+func NewKeyTrackerWithDevices(ctx context.Context, input Input, output Output, provider focus.Provider, cfg *config.Config, agent agent, stats stats) (*KeyTracker, error) {
+	return newKeyTracker(ctx, input, output, provider, cfg, agent, stats, false)
+}
+
+// NewDryRunKeyTracker creates a new keyTracker struct like
+// NewKeyTrackerWithDevices, but which does not save any words it learns or
+// that are added to the ignore list, and does not watch the corrections files
+// for changes. It is used to replay recorded sessions.
+func NewDryRunKeyTracker(ctx context.Context, input Input, output Output, provider focus.Provider, cfg *config.Config, agent agent, stats stats) (*KeyTracker, error) {
+	return newKeyTracker(ctx, input, output, provider, cfg, agent, stats, true)
+}
+
+func newKeyTracker(ctx context.Context, input Input, output Output, provider focus.Provider, cfg *config.Config, agent agent, stats stats, dryRun bool) (*KeyTracker, error) {
+	c, err := corrections.NewCorrections(cfg.Languages.Active...)
 	if err != nil {
 		return nil, err
 	}
+	if !dryRun {
+		if err := c.Watch(ctx); err != nil {
+			log.Warn().Err(err).Msg("Could not watch corrections files, changes will require a restart.")
+		}
+	}
+	return startKeyTracker(ctx, input, output, c, provider, cfg, agent, stats, dryRun), nil
+}
+
+// startKeyTracker creates a keyTracker struct using the given corrections and
+// starts it.
+func startKeyTracker(ctx context.Context, input Input, output Output, c *corrections.Corrections, provider focus.Provider, cfg *config.Config, agent agent, stats stats, dryRun bool) *KeyTracker {
+	var err error
+	kt := &KeyTracker{
+		input:       input,
+		output:      output,
+		paused:      false,
+		ToggleCh:    make(chan bool),
+		LanguageCh:  make(chan []string),
+		wipeCh:      make(chan struct{}, 1),
+		Done:        make(chan struct{}),
+		rejected:    make(map[string]bool),
+		dryRun:      dryRun,
+		languages:   cfg.Languages.Active,
+		shortcuts:   cfg.Shortcuts,
+		corrections: c,
+	}
+	if !dryRun && cfg.Languages.FollowLayout {
+		go kt.followLayout(ctx, cfg.Languages)
+	}
+	if cfg.SpellCheck.Enabled {
+		kt.spellChecker, err = corrections.NewSpellChecker(cfg.SpellCheck.WordList,
+			cfg.SpellCheck.MaxDistance, cfg.SpellCheck.Confidence)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not load spell check word list, spell checking disabled.")
+		}
+	}
 
+	if provider != nil {
+		kt.focus = newFocusTracker(provider, cfg)
+		go kt.trackFocus(ctx)
+	}
+	go kt.controlKeyTracker(ctx)
 	go func() {
 		correctionCh := make(chan *Correction)
 		wordCh := make(chan *Correction)
@@ -184,24 +484,21 @@ func NewKeyTracker(ctx context.Context, agent agent, stats stats) (*KeyTracker,
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			kt.slurpWords(ctx, wordCh, stats)
+			kt.slurpWords(ctx, wordCh, agent, stats)
 		}()
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			kt.checkWord(ctx, wordCh, correctionCh, correctionsList, stats)
+			kt.checkWord(ctx, wordCh, correctionCh, stats)
 		}()
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			kt.correctWord(ctx, correctionCh, agent, stats)
 		}()
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			kt.controlKeyTracker(ctx)
-		}()
 		wg.Wait()
+		kt.output.Close()
+		close(kt.Done)
 	}()
-	return kt, nil
+	return kt
 }
@@ -0,0 +1,207 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/joshuar/autocorrector/internal/focus"
+)
+
+type testStats struct{}
+
+func (testStats) IncKeyCounter()            {}
+func (testStats) IncBackspaceCounter()      {}
+func (testStats) IncCheckedCounter()        {}
+func (testStats) IncCorrectedCounter()      {}
+func (testStats) IncSpellCheckedCounter()   {}
+func (testStats) IncSpellCorrectedCounter() {}
+func (testStats) IncSpellRevertedCounter()  {}
+
+type testAgent struct {
+	notifications chan *Correction
+	suggestions   chan *corrections.Suggestion
+}
+
+func newTestAgent() *testAgent {
+	return &testAgent{
+		notifications: make(chan *Correction, 100),
+		suggestions:   make(chan *corrections.Suggestion, 100),
+	}
+}
+
+func (a *testAgent) NotificationCh() chan *Correction { return a.notifications }
+
+func (a *testAgent) SuggestionCh() chan *corrections.Suggestion { return a.suggestions }
+
+func (a *testAgent) Toggle() {}
+
+// newTestKeyTracker starts a dry run keytracker using only the given
+// corrections file contents.
+func newTestKeyTracker(t *testing.T, toml string) (*KeyTracker, ChannelInput, *RecordingOutput, *testAgent) {
+	t.Helper()
+	return startTestKeyTracker(t, map[string]string{"corrections.toml": toml}, nil, &config.Config{})
+}
+
+// startTestKeyTracker starts a dry run keytracker using only the given
+// corrections files, by name, following the focused window from the provider
+// if one is given.
+func startTestKeyTracker(t *testing.T, files map[string]string, provider focus.Provider, cfg *config.Config) (*KeyTracker, ChannelInput, *RecordingOutput, *testAgent) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for name, contents := range files {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+	c, err := corrections.LoadFiles(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	input := make(ChannelInput)
+	output := &RecordingOutput{}
+	agent := newTestAgent()
+	kt := startKeyTracker(ctx, input, output, c, provider, cfg, agent, testStats{}, true)
+	return kt, input, output, agent
+}
+
+// typeKeys sends a key release for each rune, with '\b' sent as backspace.
+func typeKeys(input ChannelInput, keys string) {
+	for _, r := range keys {
+		if r == '\b' {
+			input <- KeyEvent{Backspace: true, State: KeyRelease}
+			continue
+		}
+		input <- KeyEvent{Rune: r, State: KeyRelease}
+	}
+}
+
+// flushKeys waits until the keys already sent have been handled, by sending
+// a key press that is ignored once they have been.
+func flushKeys(input ChannelInput) {
+	input <- KeyEvent{State: KeyPress}
+}
+
+// waitDone closes the input and waits for the keytracker to stop.
+func waitDone(t *testing.T, kt *KeyTracker, input ChannelInput) {
+	t.Helper()
+	close(input)
+	select {
+	case <-kt.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("keytracker did not stop")
+	}
+}
+
+func TestPhraseAfterCorrection(t *testing.T) {
+	kt, input, output, _ := newTestKeyTracker(t, `
+alot = "a lot"
+"alot of" = "a lot of"
+`)
+	typeKeys(input, "alot of ")
+	waitDone(t, kt, input)
+	// alot is corrected as soon as it is typed, so it is no longer on screen
+	// to be part of a phrase with of
+	if got, want := output.Typed(), "\b\b\b\b\ba lot "; got != want {
+		t.Errorf("typed %q, want %q", got, want)
+	}
+}
+
+func TestCorrections(t *testing.T) {
+	const toml = `
+teh = "the"
+"could of" = "could have"
+
+[snippets]
+todo = "TODO({cursor})"
+`
+	tests := []struct {
+		name string
+		// steps are typed in turn, waiting for a correction after each but
+		// the last, with '\b' typed as backspace
+		steps []string
+		want  string
+	}{
+		{
+			name:  "word",
+			steps: []string{"teh "},
+			want:  "\b\b\b\bthe ",
+		},
+		{
+			name:  "correct word",
+			steps: []string{"the "},
+			want:  "",
+		},
+		{
+			name:  "punctuation",
+			steps: []string{"teh."},
+			want:  "\b\b\b\bthe.",
+		},
+		{
+			name:  "title case",
+			steps: []string{"Teh "},
+			want:  "\b\b\b\bThe ",
+		},
+		{
+			name:  "upper case",
+			steps: []string{"TEH "},
+			want:  "\b\b\b\bTHE ",
+		},
+		{
+			name:  "phrase",
+			steps: []string{"could of "},
+			want:  "\b\b\b\b\b\b\b\b\bcould have ",
+		},
+		{
+			name:  "undo",
+			steps: []string{"teh ", "\b"},
+			want:  "\b\b\b\bthe \b\b\bteh ",
+		},
+		{
+			name:  "undone word not corrected again",
+			steps: []string{"teh ", "\bteh "},
+			want:  "\b\b\b\bthe \b\b\bteh ",
+		},
+		{
+			name:  "snippet cursor",
+			steps: []string{"todo "},
+			want:  "\b\b\b\b\bTODO() ←←",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kt, input, output, agent := newTestKeyTracker(t, toml)
+			for i, step := range tt.steps {
+				typeKeys(input, step)
+				if i == len(tt.steps)-1 {
+					break
+				}
+				select {
+				case <-agent.notifications:
+				case <-time.After(5 * time.Second):
+					t.Fatal("no correction made")
+				}
+			}
+			waitDone(t, kt, input)
+			if got := output.Typed(); got != tt.want {
+				t.Errorf("typed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
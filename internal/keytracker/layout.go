@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// layoutPollInterval is how often the keyboard layout is checked when the
+// active language follows it.
+const layoutPollInterval = 2 * time.Second
+
+// englishLayouts are common keyboard layouts that are not named after their
+// language.
+var englishLayouts = map[string]bool{"us": true, "gb": true, "au": true, "ie": true, "nz": true}
+
+// gnomeSourceRe matches the first xkb input source in the output of
+// gsettings, such as ('xkb', 'de').
+var gnomeSourceRe = regexp.MustCompile(`\('xkb', '([^']+)'\)`)
+
+// layoutCommand finds the current keyboard layout with some tool, returning
+// the layout name or an empty string.
+type layoutCommand func() (string, error)
+
+// layoutCommands are tried in turn to find the tool that can report the
+// current keyboard layout.
+var layoutCommands = []layoutCommand{
+	func() (string, error) {
+		out, err := exec.Command("xkb-switch", "-p").Output()
+		return strings.TrimSpace(string(out)), err
+	},
+	func() (string, error) {
+		out, err := exec.Command("gsettings", "get", "org.gnome.desktop.input-sources", "mru-sources").Output()
+		if m := gnomeSourceRe.FindSubmatch(out); m != nil {
+			return string(m[1]), err
+		}
+		return "", err
+	},
+	func() (string, error) {
+		out, err := exec.Command("setxkbmap", "-query").Output()
+		for _, line := range strings.Split(string(out), "\n") {
+			if layouts, ok := strings.CutPrefix(line, "layout:"); ok {
+				// only the first layout can be known from setxkbmap
+				return strings.Split(strings.TrimSpace(layouts), ",")[0], err
+			}
+		}
+		return "", err
+	},
+}
+
+// findLayoutCommand returns the first of the layoutCommands that reports a
+// keyboard layout, along with that layout.
+func findLayoutCommand() (layoutCommand, string, error) {
+	var errs error
+	for _, cmd := range layoutCommands {
+		layout, err := currentLayout(cmd)
+		if err == nil {
+			return cmd, layout, nil
+		}
+		errs = errors.Join(errs, err)
+	}
+	return nil, "", errors.Join(errors.New("could not find the keyboard layout"), errs)
+}
+
+// currentLayout returns the name of the current keyboard layout, without any
+// variant, such as de for de(nodeadkeys).
+func currentLayout(cmd layoutCommand) (string, error) {
+	layout, err := cmd()
+	if err != nil {
+		return "", err
+	}
+	if layout == "" {
+		return "", errors.New("no keyboard layout reported")
+	}
+	layout, _, _ = strings.Cut(layout, "(")
+	layout, _, _ = strings.Cut(layout, "+")
+	return layout, nil
+}
+
+// layoutLanguage returns the language for a keyboard layout. Layouts are
+// assumed to be named after their language unless mapped in the config.
+func layoutLanguage(layout string, layouts map[string]string) string {
+	if lang, ok := layouts[layout]; ok {
+		return lang
+	}
+	if englishLayouts[layout] {
+		return "en"
+	}
+	return layout
+}
+
+// followLayout switches the corrections language whenever the keyboard layout
+// changes, until the context is cancelled or the keytracker stops. The tool
+// used to find the layout is chosen once, so that only it is run each time
+// the layout is checked.
+func (kt *KeyTracker) followLayout(ctx context.Context, cfg config.Languages) {
+	cmd, layout, err := findLayoutCommand()
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot find the keyboard layout, not following it.")
+		return
+	}
+	ticker := time.NewTicker(layoutPollInterval)
+	defer ticker.Stop()
+	var last string
+	for {
+		switch {
+		case err != nil:
+			log.Debug().Err(err).Msg("Could not check keyboard layout.")
+		case layout != last:
+			last = layout
+			lang := layoutLanguage(layout, cfg.Layouts)
+			log.Debug().Str("layout", layout).Str("language", lang).Msg("Keyboard layout changed.")
+			kt.setLanguages([]string{lang})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-kt.Done:
+			return
+		case <-ticker.C:
+		}
+		layout, err = currentLayout(cmd)
+	}
+}
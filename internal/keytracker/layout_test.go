@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindLayoutCommand(t *testing.T) {
+	var calls []int
+	command := func(i int, layout string, err error) layoutCommand {
+		return func() (string, error) {
+			calls = append(calls, i)
+			return layout, err
+		}
+	}
+	old := layoutCommands
+	t.Cleanup(func() { layoutCommands = old })
+	layoutCommands = []layoutCommand{
+		command(0, "", errors.New("not installed")),
+		command(1, "", nil),
+		command(2, "de(nodeadkeys)", nil),
+		command(3, "fr", nil),
+	}
+
+	cmd, layout, err := findLayoutCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout != "de" {
+		t.Errorf("layout is %q, want de", layout)
+	}
+	calls = nil
+	if _, err := currentLayout(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0] != 2 {
+		t.Errorf("commands run %v, want only the one found", calls)
+	}
+
+	layoutCommands = layoutCommands[:2]
+	if _, _, err := findLayoutCommand(); err == nil {
+		t.Error("found a layout when no command works")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+// retypeTracker watches for a word being deleted and another typed in its
+// place, either while the word is still being typed or after it has been
+// completed, so that the pair can be learnt as a possible correction.
+type retypeTracker struct {
+	// deleting is true while backspace is being pressed repeatedly.
+	deleting bool
+	// run is the current word as it was before the current run of
+	// backspaces started.
+	run string
+	// last is the most recently completed word and erased is how many
+	// characters before the current word have been deleted since.
+	last   string
+	erased int
+	// deleted is a word that has been completely deleted.
+	deleted string
+}
+
+// backspace records backspace being pressed. buffer is the current word
+// before the backspace is applied.
+func (r *retypeTracker) backspace(buffer string) {
+	if !r.deleting {
+		r.deleting = true
+		r.run = buffer
+	}
+	switch {
+	case buffer != "":
+		// deleting the word currently being typed
+		if utf8.RuneCountInString(buffer) == 1 && r.run != "" {
+			r.deleted = r.run
+		}
+	case r.last != "":
+		// deleting the previous word and its punctuation mark
+		r.erased++
+		if r.erased == utf8.RuneCountInString(r.last)+1 {
+			r.deleted = r.last
+		}
+	}
+}
+
+// key records any other key being pressed.
+func (r *retypeTracker) key() {
+	r.deleting = false
+}
+
+// word records a word being completed. If a word was deleted before it was
+// typed, the deleted word is returned.
+func (r *retypeTracker) word(word string) (string, bool) {
+	deleted := r.deleted
+	r.deleting = false
+	r.deleted = ""
+	r.last = word
+	r.erased = 0
+	return deleted, deleted != ""
+}
+
+func (r *retypeTracker) reset() {
+	*r = retypeTracker{}
+}
+
+// learn records that typo was deleted and word typed in its place. If this
+// results in a new suggested correction, the agent is told about it.
+func (kt *KeyTracker) learn(typo, word string, agent agent) {
+	if kt.dryRun {
+		log.Debug().Str("typo", typo).Str("correction", word).Msg("Observed retyped word.")
+		return
+	}
+	if suggestion, ok := kt.corrections.Learn(typo, word); ok {
+		log.Info().Str("typo", suggestion.Typo).Str("correction", suggestion.Correction).
+			Msg("Learnt a new correction.")
+		agent.SuggestionCh() <- suggestion
+	}
+}
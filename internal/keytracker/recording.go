@@ -0,0 +1,67 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"strings"
+	"sync"
+)
+
+// ChannelInput is an Input that reads key events from a channel. It can be
+// used to drive the keytracker from recorded or generated events. Close the
+// channel once all events have been sent.
+type ChannelInput chan KeyEvent
+
+func (c ChannelInput) Events() <-chan KeyEvent {
+	return c
+}
+
+// RecordingOutput is an Output that records what would have been typed
+// instead of typing it.
+type RecordingOutput struct {
+	typed  strings.Builder
+	mu     sync.Mutex
+	closed bool
+}
+
+func (r *RecordingOutput) TypeBackspace() {
+	r.mu.Lock()
+	r.typed.WriteRune('\b')
+	r.mu.Unlock()
+}
+
+func (r *RecordingOutput) TypeString(s string) {
+	r.mu.Lock()
+	r.typed.WriteString(s)
+	r.mu.Unlock()
+}
+
+func (r *RecordingOutput) TypeLeft() {
+	r.mu.Lock()
+	r.typed.WriteRune('←')
+	r.mu.Unlock()
+}
+
+func (r *RecordingOutput) Close() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+}
+
+// Typed returns everything typed so far, with each backspace recorded as '\b'
+// and each move of the caret to the left as '←'.
+func (r *RecordingOutput) Typed() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.typed.String()
+}
+
+// Closed reports whether the output has been closed.
+func (r *RecordingOutput) Closed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/focus"
+	"github.com/rs/zerolog/log"
+)
+
+// secureMatcher recognises windows where secrets are typed.
+type secureMatcher struct {
+	apps   []string
+	titles []*regexp.Regexp
+}
+
+func newSecureMatcher(cfg config.SecureInput) *secureMatcher {
+	m := &secureMatcher{apps: cfg.Apps}
+	for _, expr := range cfg.Titles {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			log.Warn().Err(err).Str("title", expr).Msg("Ignoring invalid secure input title.")
+			continue
+		}
+		m.titles = append(m.titles, re)
+	}
+	return m
+}
+
+// matches reports whether secrets may be typed in the window.
+func (m *secureMatcher) matches(w focus.Window) bool {
+	for _, app := range m.apps {
+		if w.Is(app) {
+			return true
+		}
+	}
+	for _, re := range m.titles {
+		if re.MatchString(w.Title) {
+			return true
+		}
+	}
+	return false
+}
+
+// setSecure starts or stops ignoring key presses because a window where
+// secrets are typed has the focus. When starting, anything already typed is
+// wiped, as it may be the start of a secret typed before the window was
+// noticed.
+func (kt *KeyTracker) setSecure(secure bool) {
+	if kt.secure.Swap(secure) == secure {
+		return
+	}
+	if !secure {
+		log.Info().Msg("Secure input finished, resuming.")
+		return
+	}
+	log.Info().Msg("Secure input detected, pausing.")
+	kt.takeLastCorrection()
+	select {
+	case kt.wipeCh <- struct{}{}:
+	default:
+		// a wipe is already pending
+	}
+}
+
+// wipeBuffer empties the buffer, overwriting what it held so that typed
+// characters do not stay in memory.
+func wipeBuffer(buf *bytes.Buffer) {
+	b := buf.Bytes()
+	b = b[:cap(b)]
+	for i := range b {
+		b[i] = 0
+	}
+	buf.Reset()
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/focus"
+	"github.com/rs/zerolog/log"
+)
+
+// maxReplayDelay is the longest pause between two events when replaying a
+// session, so that idle periods in a recording do not have to be sat through.
+const maxReplayDelay = time.Second
+
+var keyStates = map[KeyState]string{
+	KeyPress:   "press",
+	KeyRelease: "release",
+	KeyHold:    "hold",
+}
+
+func (s KeyState) String() string {
+	if name, ok := keyStates[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("KeyState(%d)", int(s))
+}
+
+func parseKeyState(name string) (KeyState, error) {
+	for state, stateName := range keyStates {
+		if stateName == name {
+			return state, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key state %q", name)
+}
+
+// recordedEvent is a key event as stored in a session recording, one JSON
+// object per line.
+type recordedEvent struct {
+	// Offset is the number of milliseconds since the start of the recording.
+	Offset    int64  `json:"ms"`
+	Key       string `json:"key,omitempty"`
+	Backspace bool   `json:"backspace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	State     string `json:"state"`
+}
+
+// redactRune hides which letter or digit was typed, keeping its case, so that
+// a recording can be shared without revealing what was written. Spaces and
+// punctuation are kept, as they delimit the words the keytracker checks.
+func redactRune(r rune) rune {
+	switch {
+	case unicode.IsUpper(r):
+		return 'X'
+	case unicode.IsLetter(r):
+		return 'x'
+	case unicode.IsDigit(r):
+		return '0'
+	default:
+		return r
+	}
+}
+
+// RecordSession writes the events from the given input to w until the input
+// has no more events or the context is cancelled. If redact is true, letters
+// and digits are replaced so that the recording does not contain what was
+// typed. Only the names of modifier keys are kept in a redacted recording. If
+// a focus provider is given, the focused window is checked for each event and
+// nothing is recorded while secrets may be typed in it.
+func RecordSession(ctx context.Context, input Input, w io.Writer, redact bool, provider focus.Provider, cfg config.SecureInput) error {
+	enc := json.NewEncoder(w)
+	start := time.Now()
+	secure := newSecureMatcher(cfg)
+	paused := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-input.Events():
+			if !ok {
+				return nil
+			}
+			if provider != nil {
+				focused, err := provider.Focused()
+				switch {
+				case errors.Is(err, exec.ErrNotFound):
+					log.Warn().Err(err).Msg("Cannot find the focused window, secure input detection disabled.")
+					provider = nil
+				case err != nil:
+					log.Debug().Err(err).Msg("Could not find focused window.")
+				case secure.matches(focused) != paused:
+					paused = !paused
+					if paused {
+						log.Info().Msg("Secure input detected, pausing recording.")
+					} else {
+						log.Info().Msg("Secure input finished, resuming recording.")
+					}
+				}
+			}
+			if paused {
+				continue
+			}
+			event := recordedEvent{
+				Offset:    time.Since(start).Milliseconds(),
+				Backspace: k.Backspace,
+				State:     k.State.String(),
+			}
+			if k.Rune != 0 {
+				r := k.Rune
+				if redact {
+					r = redactRune(r)
+				}
+				event.Key = string(r)
+			}
+			if _, ok := modifierKeys[k.Name]; ok || !redact {
+				event.Name = k.Name
+			}
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readSession reads a session recorded with RecordSession.
+func readSession(r io.Reader) ([]recordedEvent, error) {
+	var events []recordedEvent
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event recordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		if _, err := parseKeyState(event.State); err != nil {
+			return nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// NewReplayInput returns an Input that replays a session recorded with
+// RecordSession. The events are sent with the same timing as they were
+// recorded, as the keytracker relies on it to tell when a correction is being
+// undone. The input is closed after the last event.
+func NewReplayInput(ctx context.Context, r io.Reader) (Input, error) {
+	events, err := readSession(r)
+	if err != nil {
+		return nil, err
+	}
+	input := make(ChannelInput)
+	go func() {
+		defer close(input)
+		var last int64
+		for _, event := range events {
+			delay := time.Duration(event.Offset-last) * time.Millisecond
+			if delay > maxReplayDelay {
+				delay = maxReplayDelay
+			}
+			last = event.Offset
+			k := KeyEvent{Backspace: event.Backspace, Name: event.Name}
+			k.State, _ = parseKeyState(event.State)
+			if event.Key != "" {
+				k.Rune, _ = utf8.DecodeRuneInString(event.Key)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case input <- k:
+			}
+		}
+	}()
+	return input, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/joshuar/autocorrector/internal/focus"
+)
+
+func TestRecordSessionSecureInput(t *testing.T) {
+	cfg, err := config.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := focus.NewStatic(focus.Window{Class: "kitty", Title: "~"})
+	input := make(ChannelInput)
+	var recording bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- RecordSession(context.Background(), input, &recording, false, window, cfg.SecureInput)
+	}()
+
+	typeKeys(input, "ls ")
+	flushKeys(input)
+	window.Set(focus.Window{Class: "KeePassXC", Title: "Unlock Database"})
+	typeKeys(input, "hunter2")
+	flushKeys(input)
+	window.Set(focus.Window{Class: "kitty", Title: "~"})
+	typeKeys(input, "\n")
+	close(input)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := readSession(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typed string
+	for _, event := range events {
+		typed += event.Key
+	}
+	if typed != "ls \n" {
+		t.Errorf("recorded %q, want %q", typed, "ls \n")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joshuar/autocorrector/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// shortcut is an action triggered by a key chord.
+type shortcut int
+
+const (
+	noShortcut shortcut = iota
+	// toggleShortcut turns corrections on and off.
+	toggleShortcut
+	// correctShortcut corrects the word being typed straight away.
+	correctShortcut
+	// skipShortcut leaves the word being typed uncorrected.
+	skipShortcut
+)
+
+// modifier is a set of modifier keys held down.
+type modifier int
+
+const (
+	modCtrl modifier = 1 << iota
+	modShift
+	modAlt
+	modMeta
+)
+
+// modifierKeys are the names of the modifier keys, as in KeyEvent.Name.
+var modifierKeys = map[string]modifier{
+	"LEFTCTRL":   modCtrl,
+	"RIGHTCTRL":  modCtrl,
+	"LEFTSHIFT":  modShift,
+	"RIGHTSHIFT": modShift,
+	"LEFTALT":    modAlt,
+	"RIGHTALT":   modAlt,
+	"LEFTMETA":   modMeta,
+	"RIGHTMETA":  modMeta,
+}
+
+// modifierNames are the names of the modifiers when writing a chord.
+var modifierNames = map[string]modifier{
+	"CTRL":    modCtrl,
+	"CONTROL": modCtrl,
+	"SHIFT":   modShift,
+	"ALT":     modAlt,
+	"META":    modMeta,
+	"SUPER":   modMeta,
+	"WIN":     modMeta,
+}
+
+// chord is a key pressed while holding exactly the given modifiers.
+type chord struct {
+	mods modifier
+	key  string
+}
+
+// parseChord reads a chord such as Ctrl+Alt+Shift+A. Names are not case
+// sensitive. The key is named as in KeyEvent.Name, such as A, 1, SPACE or
+// F12.
+func parseChord(s string) (chord, error) {
+	parts := strings.Split(strings.ToUpper(strings.ReplaceAll(s, " ", "")), "+")
+	var c chord
+	for _, part := range parts[:len(parts)-1] {
+		mod, ok := modifierNames[part]
+		if !ok {
+			return chord{}, fmt.Errorf("unknown modifier %q in %q", part, s)
+		}
+		c.mods |= mod
+	}
+	c.key = parts[len(parts)-1]
+	_, isModifierKey := modifierKeys[c.key]
+	_, isModifier := modifierNames[c.key]
+	if isModifierKey || isModifier || c.key == "" {
+		return chord{}, fmt.Errorf("missing key in %q", s)
+	}
+	return c, nil
+}
+
+// shortcuts detects key chords in the stream of key events.
+type shortcuts struct {
+	chords map[chord]shortcut
+	// held are the modifier keys held down, by name.
+	held map[string]bool
+	// swallowed is the key of the last chord pressed, whose later events are
+	// ignored.
+	swallowed string
+}
+
+// newShortcuts creates the shortcuts set in the config. Chords that cannot be
+// read are logged and left out.
+func newShortcuts(cfg config.Shortcuts) *shortcuts {
+	s := &shortcuts{
+		chords: make(map[chord]shortcut),
+		held:   make(map[string]bool),
+	}
+	for action, spec := range map[shortcut]string{
+		toggleShortcut:  cfg.Toggle,
+		correctShortcut: cfg.Correct,
+		skipShortcut:    cfg.Skip,
+	} {
+		if spec == "" {
+			continue
+		}
+		c, err := parseChord(spec)
+		if err != nil {
+			log.Warn().Err(err).Msg("Ignoring invalid shortcut.")
+			continue
+		}
+		s.chords[c] = action
+	}
+	return s
+}
+
+// match follows the modifier keys held down and reports the shortcut
+// triggered by the key event, if any. Events for modifier keys, and for the
+// key of a chord after it has been pressed, are also reported, as
+// noShortcut, so that they are not treated as typed text.
+func (s *shortcuts) match(k KeyEvent) (shortcut, bool) {
+	if _, ok := modifierKeys[k.Name]; ok {
+		s.held[k.Name] = k.State != KeyRelease
+		return noShortcut, true
+	}
+	if k.Name != "" && k.Name == s.swallowed {
+		if k.State == KeyRelease {
+			s.swallowed = ""
+		}
+		return noShortcut, true
+	}
+	if k.State != KeyPress || k.Name == "" {
+		return noShortcut, false
+	}
+	var mods modifier
+	for name, down := range s.held {
+		if down {
+			mods |= modifierKeys[name]
+		}
+	}
+	action, ok := s.chords[chord{mods: mods, key: k.Name}]
+	if !ok {
+		return noShortcut, false
+	}
+	s.swallowed = k.Name
+	return action, true
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"testing"
+
+	"github.com/joshuar/autocorrector/internal/config"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    chord
+		wantErr bool
+	}{
+		{spec: "Ctrl+Alt+Shift+A", want: chord{mods: modCtrl | modAlt | modShift, key: "A"}},
+		{spec: "ctrl + shift + space", want: chord{mods: modCtrl | modShift, key: "SPACE"}},
+		{spec: "Control+Super+F12", want: chord{mods: modCtrl | modMeta, key: "F12"}},
+		{spec: "Win+Meta+1", want: chord{mods: modMeta, key: "1"}},
+		{spec: "F9", want: chord{key: "F9"}},
+		{spec: "Alt+Semicolon", want: chord{mods: modAlt, key: "SEMICOLON"}},
+		{spec: "Hyper+A", wantErr: true},
+		{spec: "Ctrl+Shift", wantErr: true},
+		{spec: "Ctrl+LeftShift", wantErr: true},
+		{spec: "Ctrl+", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseChord(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChord(%q) error = %v, want error %t", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseChord(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestNewShortcutsInvalid(t *testing.T) {
+	s := newShortcuts(config.Shortcuts{Toggle: "Ctrl+Alt+A", Correct: "Hyper+C", Skip: ""})
+	if len(s.chords) != 1 || s.chords[chord{mods: modCtrl | modAlt, key: "A"}] != toggleShortcut {
+		t.Errorf("chords are %v, want only the toggle shortcut", s.chords)
+	}
+}
+
+func TestShortcutsMatch(t *testing.T) {
+	press := func(name string) KeyEvent { return KeyEvent{Name: name, State: KeyPress} }
+	hold := func(name string) KeyEvent { return KeyEvent{Name: name, State: KeyHold} }
+	release := func(name string) KeyEvent { return KeyEvent{Name: name, State: KeyRelease} }
+	type step struct {
+		event    KeyEvent
+		want     shortcut
+		consumed bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "chord",
+			steps: []step{
+				{event: press("LEFTCTRL"), consumed: true},
+				{event: press("RIGHTALT"), consumed: true},
+				{event: press("A"), want: toggleShortcut, consumed: true},
+				{event: hold("A"), consumed: true},
+				{event: release("A"), consumed: true},
+				{event: release("RIGHTALT"), consumed: true},
+				{event: release("LEFTCTRL"), consumed: true},
+				// the key is typed as usual once the chord is over
+				{event: press("A")},
+				{event: release("A")},
+			},
+		},
+		{
+			name: "modifiers held down repeat",
+			steps: []step{
+				{event: press("LEFTCTRL"), consumed: true},
+				{event: hold("LEFTCTRL"), consumed: true},
+				{event: press("LEFTSHIFT"), consumed: true},
+				{event: press("SPACE"), want: correctShortcut, consumed: true},
+				{event: release("SPACE"), consumed: true},
+			},
+		},
+		{
+			name: "extra modifier",
+			steps: []step{
+				{event: press("LEFTCTRL"), consumed: true},
+				{event: press("LEFTALT"), consumed: true},
+				{event: press("LEFTSHIFT"), consumed: true},
+				{event: press("SPACE")},
+			},
+		},
+		{
+			name: "missing modifier",
+			steps: []step{
+				{event: press("LEFTALT"), consumed: true},
+				{event: press("A")},
+			},
+		},
+		{
+			name: "modifier released",
+			steps: []step{
+				{event: press("LEFTCTRL"), consumed: true},
+				{event: press("LEFTALT"), consumed: true},
+				{event: release("LEFTALT"), consumed: true},
+				{event: press("A")},
+			},
+		},
+		{
+			name: "either side",
+			steps: []step{
+				{event: press("RIGHTCTRL"), consumed: true},
+				{event: press("LEFTALT"), consumed: true},
+				{event: press("A"), want: toggleShortcut, consumed: true},
+			},
+		},
+		{
+			name: "no modifiers",
+			steps: []step{
+				{event: press("F9"), want: skipShortcut, consumed: true},
+				{event: release("F9"), consumed: true},
+				{event: press("F9"), want: skipShortcut, consumed: true},
+			},
+		},
+		{
+			name: "unnamed key",
+			steps: []step{
+				{event: press("LEFTCTRL"), consumed: true},
+				{event: KeyEvent{Rune: 'a', State: KeyPress}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShortcuts(config.Shortcuts{Toggle: "Ctrl+Alt+A", Correct: "Ctrl+Shift+Space", Skip: "F9"})
+			for i, step := range tt.steps {
+				got, consumed := s.match(step.event)
+				if got != step.want || consumed != step.consumed {
+					t.Errorf("step %d, %+v: match = %v, %t, want %v, %t", i, step.event, got, consumed, step.want, step.consumed)
+				}
+			}
+		})
+	}
+}
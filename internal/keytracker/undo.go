@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package keytracker
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+// setLastCorrection records the correction just made so that it can be undone
+// if the next key pressed is backspace.
+func (kt *KeyTracker) setLastCorrection(correction *Correction) {
+	kt.mu.Lock()
+	kt.lastCorrection = correction
+	kt.mu.Unlock()
+}
+
+// takeLastCorrection returns the last correction made, if any, and forgets it.
+// It is called for every key press, so a correction can only be undone by the
+// key immediately following it.
+func (kt *KeyTracker) takeLastCorrection() *Correction {
+	kt.mu.Lock()
+	defer kt.mu.Unlock()
+	correction := kt.lastCorrection
+	kt.lastCorrection = nil
+	return correction
+}
+
+// isRejected reports whether a correction for the word has been undone during
+// this session. Like the ignore list, this does not depend on how the word is
+// capitalised.
+func (kt *KeyTracker) isRejected(word string) bool {
+	kt.mu.Lock()
+	defer kt.mu.Unlock()
+	return kt.rejected[strings.ToLower(word)]
+}
+
+// undoCorrection reverts a correction after backspace has been pressed
+// immediately following it. The backspace has already removed the punctuation
+// mark (or, for a word corrected before it was finished, the last character
+// of the replacement), so the rest of the replacement is erased and the
+// original word and punctuation mark are typed back out.
+func (kt *KeyTracker) undoCorrection(correction *Correction, stats stats) {
+	log.Debug().Msgf("Undoing correction %s to %s", correction.Word, correction.Correction)
+	erase := utf8.RuneCountInString(correction.Correction)
+	if correction.Punct == 0 {
+		erase--
+	}
+	for i := 0; i < erase; i++ {
+		kt.output.TypeBackspace()
+	}
+	kt.output.TypeString(correction.Word + correction.punct())
+	kt.reject(correction, stats)
+}
+
+// reject stops the word of a reverted correction from being corrected again
+// this session and records the rejection, so that words that are repeatedly
+// reverted end up on the persistent ignore list. Reverted spell check
+// corrections are also counted, to measure the accuracy of the spell checker.
+func (kt *KeyTracker) reject(correction *Correction, stats stats) {
+	kt.mu.Lock()
+	kt.rejected[strings.ToLower(correction.Word)] = true
+	kt.mu.Unlock()
+	if correction.SpellChecked {
+		stats.IncSpellRevertedCounter()
+	}
+	if !kt.dryRun {
+		kt.corrections.Reject(correction.Word)
+	}
+}
+
+// revertTracker watches for a correction being reverted by hand: the corrected
+// word and its punctuation mark being erased and the original word typed
+// again.
+type revertTracker struct {
+	correction *Correction
+	erased     int
+}
+
+// track starts watching the given correction.
+func (r *revertTracker) track(correction *Correction) {
+	r.correction = correction
+	r.erased = 0
+}
+
+// erase records a character before the current word being deleted.
+func (r *revertTracker) erase() {
+	if r.correction != nil {
+		r.erased++
+	}
+}
+
+func (r *revertTracker) reset() {
+	r.correction = nil
+}
+
+// reverted is called for each word typed. It returns the tracked correction if
+// it has been erased and the word is the original word typed again. Either
+// way, the correction is no longer tracked.
+func (r *revertTracker) reverted(word string) *Correction {
+	correction := r.correction
+	r.correction = nil
+	if correction == nil || word != correction.Word {
+		return nil
+	}
+	if r.erased < utf8.RuneCountInString(correction.Correction+correction.punct()) {
+		return nil
+	}
+	return correction
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	base := filepath.FromSlash("/repo")
+	tests := []struct {
+		name  string
+		lines []string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{name: "any depth", lines: []string{"*.log"}, path: "a.log", want: true},
+		{name: "any depth nested", lines: []string{"*.log"}, path: "dir/a.log", want: true},
+		{name: "whole name", lines: []string{"*.log"}, path: "a.logs", want: false},
+		{name: "negation", lines: []string{"*.log", "!keep.log"}, path: "keep.log", want: false},
+		{name: "negation of others", lines: []string{"*.log", "!keep.log"}, path: "other.log", want: true},
+		{name: "last rule wins", lines: []string{"!keep.log", "*.log"}, path: "keep.log", want: true},
+		{name: "anchored", lines: []string{"/foo"}, path: "foo", want: true},
+		{name: "anchored not nested", lines: []string{"/foo"}, path: "dir/foo", want: false},
+		{name: "slash anchors", lines: []string{"doc/*.txt"}, path: "doc/a.txt", want: true},
+		{name: "star within directory", lines: []string{"doc/*.txt"}, path: "doc/sub/a.txt", want: false},
+		{name: "slash anchored not nested", lines: []string{"doc/*.txt"}, path: "x/doc/a.txt", want: false},
+		{name: "directory only", lines: []string{"foo/"}, path: "foo", isDir: true, want: true},
+		{name: "directory only nested", lines: []string{"foo/"}, path: "a/foo", isDir: true, want: true},
+		{name: "directory only file", lines: []string{"foo/"}, path: "foo", want: false},
+		{name: "leading double star", lines: []string{"**/build"}, path: "build", isDir: true, want: true},
+		{name: "leading double star nested", lines: []string{"**/build"}, path: "a/b/build", isDir: true, want: true},
+		{name: "middle double star", lines: []string{"a/**/b"}, path: "a/b", want: true},
+		{name: "middle double star deep", lines: []string{"a/**/b"}, path: "a/x/y/b", want: true},
+		{name: "trailing double star", lines: []string{"logs/**"}, path: "logs/a/b", want: true},
+		{name: "trailing double star not directory", lines: []string{"logs/**"}, path: "logs", isDir: true, want: false},
+		{name: "question mark", lines: []string{"?.txt"}, path: "a.txt", want: true},
+		{name: "question mark one character", lines: []string{"?.txt"}, path: "ab.txt", want: false},
+		{name: "class", lines: []string{"[ab].txt"}, path: "b.txt", want: true},
+		{name: "negated class", lines: []string{"[!a].txt"}, path: "a.txt", want: false},
+		{name: "comment", lines: []string{"# foo"}, path: "# foo", want: false},
+		{name: "escaped hash", lines: []string{`\#foo`}, path: "#foo", want: true},
+		{name: "escaped bang", lines: []string{`\!foo`}, path: "!foo", want: true},
+		{name: "trailing space", lines: []string{"foo  "}, path: "foo", want: true},
+		{name: "escaped trailing space", lines: []string{`foo\ `}, path: "foo ", want: true},
+		{name: "outside base", lines: []string{"*"}, path: "../other", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules ignoreRules
+			for _, line := range tt.lines {
+				if rule, ok := parseIgnoreRule(base, line); ok {
+					rules = append(rules, rule)
+				}
+			}
+			path := filepath.Join(base, filepath.FromSlash(tt.path))
+			if got := rules.ignored(path, tt.isDir); got != tt.want {
+				t.Errorf("ignored(%q) = %t, want %t", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "*.log\n!keep.log\n/build/\ntmp/\n",
+		"a.txt":               "",
+		"a.log":               "",
+		"keep.log":            "",
+		"build/out.txt":       "",
+		"src/build/b.txt":     "",
+		"src/tmp/c.txt":       "",
+		"src/.gitignore":      "*.txt\n!d.txt\n",
+		"src/d.txt":           "",
+		"src/e.txt":           "",
+		"src/sub/f.log":       "",
+		".git/config":         "",
+		"other/g.md":          "",
+		"other/nested/h.md":   "",
+		"other/nested/.git/x": "",
+	}
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	err := walk(root, func(file string) error {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		got = append(got, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		".gitignore",
+		"a.txt",
+		"keep.log",
+		"other/g.md",
+		"other/nested/h.md",
+		"src/.gitignore",
+		"src/d.txt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("walked %q, want %q", got, want)
+	}
+}
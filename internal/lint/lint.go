@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package lint finds typos in files using the corrections list.
+package lint
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+)
+
+// binarySniffLength is how much of a file is checked for a NUL byte to decide
+// whether it is binary.
+const binarySniffLength = 8000
+
+// Typo is a typo found in a file.
+type Typo struct {
+	File string
+	corrections.Match
+}
+
+// Check finds the typos in the given files and directories. Directories are
+// searched recursively, skipping anything matched by a .gitignore file, the
+// .git directory and binary files.
+func Check(c *corrections.Corrections, paths ...string) ([]Typo, error) {
+	var typos []Typo
+	for _, path := range paths {
+		err := walk(path, func(file string) error {
+			found, err := checkFile(c, file)
+			if err != nil {
+				return err
+			}
+			typos = append(typos, found...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return typos, nil
+}
+
+func checkFile(c *corrections.Corrections, file string) ([]Typo, error) {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(text[:min(len(text), binarySniffLength)], 0) >= 0 {
+		log.Debug().Str("file", file).Msg("Skipping binary file.")
+		return nil, nil
+	}
+	matches := c.Find(string(text))
+	typos := make([]Typo, 0, len(matches))
+	for _, m := range matches {
+		typos = append(typos, Typo{File: file, Match: m})
+	}
+	return typos, nil
+}
+
+// walk calls fn for each file under root that is not ignored. If root is a
+// file, fn is called for it regardless of any .gitignore files.
+func walk(root string, fn func(file string) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fn(root)
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	parentRules, err := parentIgnoreRules(abs)
+	if err != nil {
+		return err
+	}
+	rules := make(map[string]ignoreRules)
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		absPath := filepath.Join(abs, rel)
+		parent, ok := rules[filepath.Dir(absPath)]
+		if !ok {
+			parent = parentRules
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || parent.ignored(absPath, true)) {
+				return filepath.SkipDir
+			}
+			dirRules, err := parent.withFile(absPath)
+			if err != nil {
+				return err
+			}
+			rules[absPath] = dirRules
+			return nil
+		}
+		if !d.Type().IsRegular() || parent.ignored(absPath, false) {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// Formats are the names of the supported report formats.
+var Formats = []string{"plain", "json", "sarif"}
+
+// WriteReport writes the typos to w in the given format.
+func WriteReport(w io.Writer, format string, typos []Typo) error {
+	switch format {
+	case "plain":
+		return writePlain(w, typos)
+	case "json":
+		return writeJSON(w, typos)
+	case "sarif":
+		return writeSARIF(w, typos)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// writePlain writes each typo on a line as file:line:column: typo -> correction.
+func writePlain(w io.Writer, typos []Typo) error {
+	for _, t := range typos {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s -> %s\n", t.File, t.Line, t.Column, t.Typo, t.Correction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonTypo struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Typo       string `json:"typo"`
+	Correction string `json:"correction"`
+}
+
+func writeJSON(w io.Writer, typos []Typo) error {
+	report := make([]jsonTypo, 0, len(typos))
+	for _, t := range typos {
+		report = append(report, jsonTypo{
+			File:       t.File,
+			Line:       t.Line,
+			Column:     t.Column,
+			Typo:       t.Typo,
+			Correction: t.Correction,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(report)
+}
+
+// The subset of the SARIF 2.1.0 format needed to report typos, with fixes.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+		Fixes     []sarifFix      `json:"fixes"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndColumn   int `json:"endColumn"`
+	}
+	sarifFix struct {
+		Description     sarifMessage          `json:"description"`
+		ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+	}
+	sarifArtifactChange struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Replacements     []sarifReplacement    `json:"replacements"`
+	}
+	sarifReplacement struct {
+		DeletedRegion   sarifRegion  `json:"deletedRegion"`
+		InsertedContent sarifMessage `json:"insertedContent"`
+	}
+)
+
+const sarifRuleID = "typo"
+
+func writeSARIF(w io.Writer, typos []Typo) error {
+	results := make([]sarifResult, 0, len(typos))
+	for _, t := range typos {
+		artifact := sarifArtifactLocation{URI: filepath.ToSlash(t.File)}
+		region := sarifRegion{
+			StartLine:   t.Line,
+			StartColumn: t.Column,
+			EndColumn:   t.Column + utf8.RuneCountInString(t.Typo),
+		}
+		results = append(results, sarifResult{
+			RuleID:  sarifRuleID,
+			Level:   "warning",
+			Message: sarifMessage{Text: fmt.Sprintf("%s -> %s", t.Typo, t.Correction)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact, Region: region},
+			}},
+			Fixes: []sarifFix{{
+				Description: sarifMessage{Text: "Replace with " + t.Correction},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: artifact,
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   region,
+						InsertedContent: sarifMessage{Text: t.Correction},
+					}},
+				}},
+			}},
+		})
+	}
+	report := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "autocorrector",
+				InformationURI: "https://github.com/joshuar/autocorrector",
+				Rules: []sarifRule{{
+					ID:               sarifRuleID,
+					ShortDescription: sarifMessage{Text: "Word with a known correction."},
+				}},
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(report)
+}
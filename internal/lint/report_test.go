@@ -0,0 +1,82 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+)
+
+var testTypos = []Typo{
+	{File: "a.txt", Match: corrections.Match{Typo: "teh", Correction: "the", Offset: 6, Line: 2, Column: 5}},
+	{File: "b.txt", Match: corrections.Match{Typo: "naïve", Correction: "naive", Offset: 0, Line: 1, Column: 1}},
+}
+
+func TestWritePlain(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "plain", testTypos); err != nil {
+		t.Fatal(err)
+	}
+	want := "a.txt:2:5: teh -> the\nb.txt:1:1: naïve -> naive\n"
+	if got := buf.String(); got != want {
+		t.Errorf("report %q, want %q", got, want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "sarif", testTypos); err != nil {
+		t.Fatal(err)
+	}
+	var report sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(report.Runs))
+	}
+	run := report.Runs[0]
+	if run.ColumnKind != "unicodeCodePoints" {
+		t.Errorf("columnKind %q, want unicodeCodePoints", run.ColumnKind)
+	}
+	want := []struct {
+		uri, text string
+		region    sarifRegion
+	}{
+		{uri: "a.txt", text: "teh -> the", region: sarifRegion{StartLine: 2, StartColumn: 5, EndColumn: 8}},
+		// the end column counts characters, so ï is one column
+		{uri: "b.txt", text: "naïve -> naive", region: sarifRegion{StartLine: 1, StartColumn: 1, EndColumn: 6}},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(want))
+	}
+	for i, r := range run.Results {
+		w := want[i]
+		if r.RuleID != sarifRuleID || r.Message.Text != w.text {
+			t.Errorf("result %d is %s %q, want %s %q", i, r.RuleID, r.Message.Text, sarifRuleID, w.text)
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != w.uri || loc.Region != w.region {
+			t.Errorf("result %d at %s %+v, want %s %+v", i, loc.ArtifactLocation.URI, loc.Region, w.uri, w.region)
+		}
+		replacement := r.Fixes[0].ArtifactChanges[0].Replacements[0]
+		if replacement.DeletedRegion != w.region {
+			t.Errorf("result %d deletes %+v, want %+v", i, replacement.DeletedRegion, w.region)
+		}
+		if replacement.InsertedContent.Text != testTypos[i].Correction {
+			t.Errorf("result %d inserts %q, want %q", i, replacement.InsertedContent.Text, testTypos[i].Correction)
+		}
+	}
+}
+
+func TestWriteReportUnknownFormat(t *testing.T) {
+	if err := WriteReport(&bytes.Buffer{}, "xml", testTypos); err == nil {
+		t.Error("no error for unknown format")
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package lsp is a Language Server Protocol server that reports words in the
+// corrections list as diagnostics, with code actions to correct them.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+	"github.com/rs/zerolog/log"
+)
+
+const serverName = "autocorrector"
+
+// textDocumentSyncFull is the LSP sync kind where the client sends the full
+// text of a document on every change.
+const textDocumentSyncFull = 1
+
+// Server is a Language Server Protocol server for the corrections list.
+type Server struct {
+	corrections *corrections.Corrections
+	conn        *conn
+	// documents holds the text of each open document by URI.
+	documents map[string]string
+	// mu guards documents, which are also read when the corrections are
+	// reloaded.
+	mu       sync.Mutex
+	shutdown bool
+}
+
+// NewServer creates a server that reads requests from r and writes responses
+// to w, such as stdin and stdout. The diagnostics for open documents are
+// published again whenever the corrections are reloaded.
+func NewServer(c *corrections.Corrections, r io.Reader, w io.Writer) *Server {
+	s := &Server{
+		corrections: c,
+		conn:        newConn(r, w),
+		documents:   make(map[string]string),
+	}
+	c.OnReload(s.republishDiagnostics)
+	return s
+}
+
+// Run handles requests until the client sends an exit notification or closes
+// the connection.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.read()
+		var rpcErr *responseError
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, &rpcErr):
+			if err := s.conn.write(&message{ID: nullID(), Error: rpcErr}); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			if err != nil {
+				log.Warn().Err(err).Str("method", msg.Method).Msg("Could not handle notification.")
+			}
+			continue
+		}
+		response := &message{ID: msg.ID, Result: result}
+		if err != nil {
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &responseError{Code: codeInvalidParams, Message: err.Error()}
+			}
+			response.Result, response.Error = nil, rpcErr
+		} else if result == nil {
+			// a successful response must have a result, even if it is null
+			response.Result = json.RawMessage("null")
+		}
+		if err := s.conn.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   textDocumentSyncFull,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": serverName},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.documents, params.TextDocument.URI)
+		// clear the diagnostics for the closed document
+		return nil, s.conn.notify("textDocument/publishDiagnostics",
+			publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.codeActions(params), nil
+	default:
+		if msg.ID == nil {
+			// notifications that are not handled, such as initialized, are
+			// ignored
+			return nil, nil
+		}
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+// diagnostics returns a diagnostic for each typo in the document. s.mu must be
+// held.
+func (s *Server) diagnostics(uri string) []diagnostic {
+	text := s.documents[uri]
+	matches := s.corrections.Find(text)
+	diagnostics := make([]diagnostic, 0, len(matches))
+	for _, m := range matches {
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    matchRange(text, m),
+			Severity: severityInformation,
+			Source:   serverName,
+			Message:  m.Typo + " -> " + m.Correction,
+			Data:     m.Correction,
+		})
+	}
+	return diagnostics
+}
+
+func (s *Server) publishDiagnostics(uri string) error {
+	return s.conn.notify("textDocument/publishDiagnostics",
+		publishDiagnosticsParams{URI: uri, Diagnostics: s.diagnostics(uri)})
+}
+
+// republishDiagnostics publishes the diagnostics for every open document, as
+// the typos in them change when the corrections are reloaded.
+func (s *Server) republishDiagnostics() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for uri := range s.documents {
+		if err := s.publishDiagnostics(uri); err != nil {
+			log.Warn().Err(err).Str("uri", uri).Msg("Could not publish diagnostics.")
+			return
+		}
+	}
+}
+
+// codeActions returns an action to correct each typo overlapping the range.
+// s.mu must be held.
+func (s *Server) codeActions(params codeActionParams) []codeAction {
+	uri := params.TextDocument.URI
+	actions := []codeAction{}
+	for _, d := range s.diagnostics(uri) {
+		if before(d.Range.End, params.Range.Start) || before(params.Range.End, d.Range.Start) {
+			continue
+		}
+		actions = append(actions, codeAction{
+			Title:       "Replace with " + d.Data,
+			Kind:        "quickfix",
+			Diagnostics: []diagnostic{d},
+			IsPreferred: true,
+			Edit: workspaceEdit{Changes: map[string][]textEdit{
+				uri: {{Range: d.Range, NewText: d.Data}},
+			}},
+		})
+	}
+	return actions
+}
+
+func before(a, b position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
+
+// matchRange converts the position of a typo to an LSP range, which counts
+// lines from 0 and characters in UTF-16 code units.
+func matchRange(text string, m corrections.Match) lspRange {
+	lineStart := strings.LastIndexByte(text[:m.Offset], '\n') + 1
+	start := utf16Len(text[lineStart:m.Offset])
+	return lspRange{
+		Start: position{Line: m.Line - 1, Character: start},
+		End:   position{Line: m.Line - 1, Character: start + utf16Len(m.Typo)},
+	}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xFFFF {
+			// encoded as a surrogate pair
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func nullID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
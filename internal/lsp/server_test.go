@@ -0,0 +1,291 @@
+// Copyright (c) 2023 Joshua Rich <joshua.rich@gmail.com>
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshuar/autocorrector/internal/corrections"
+)
+
+// testClient talks to a server over pipes, as an editor would over stdio.
+type testClient struct {
+	t      *testing.T
+	server *Server
+	conn   *conn
+	// w writes raw bytes to the server.
+	w    io.Writer
+	done chan error
+}
+
+// newTestClient starts a server using only the given corrections file
+// contents.
+func newTestClient(t *testing.T, toml string) *testClient {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "corrections.toml")
+	if err := os.WriteFile(file, []byte(toml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := corrections.LoadFiles(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	client := &testClient{
+		t:      t,
+		server: NewServer(c, serverIn, serverOut),
+		conn:   newConn(clientIn, clientOut),
+		w:      clientOut,
+		done:   make(chan error, 1),
+	}
+	go func() {
+		client.done <- client.server.Run()
+		serverOut.Close()
+	}()
+	t.Cleanup(func() {
+		clientOut.Close()
+		clientIn.Close()
+	})
+	return client
+}
+
+// read returns the next message from the server.
+func (c *testClient) read() *message {
+	c.t.Helper()
+	type result struct {
+		msg *message
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		msg, err := c.conn.read()
+		ch <- result{msg, err}
+	}()
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			c.t.Fatal(r.err)
+		}
+		return r.msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("no message from server")
+		return nil
+	}
+}
+
+// send sends a request, or a notification if id is 0.
+func (c *testClient) send(id int, method string, params any) {
+	c.t.Helper()
+	msg := &message{Method: method}
+	if id != 0 {
+		raw := json.RawMessage(fmt.Sprint(id))
+		msg.ID = &raw
+	}
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		msg.Params = b
+	}
+	if err := c.conn.write(msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// diagnostics reads the next message, which must publish diagnostics.
+func (c *testClient) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got %q, want diagnostics", msg.Method)
+	}
+	var params publishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		c.t.Fatal(err)
+	}
+	return params
+}
+
+func (c *testClient) open(uri, text string) {
+	c.t.Helper()
+	c.send(0, "textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: uri, Text: text}})
+}
+
+func (c *testClient) exit() {
+	c.t.Helper()
+	c.send(99, "shutdown", nil)
+	if msg := c.read(); msg.Error != nil {
+		c.t.Fatalf("shutdown failed: %v", msg.Error)
+	}
+	c.send(0, "exit", nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Errorf("server stopped with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server did not stop")
+	}
+}
+
+func TestFraming(t *testing.T) {
+	client := newTestClient(t, `teh = "the"`)
+	// other headers are allowed, and two messages may arrive in one write
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	unknown := `{"jsonrpc":"2.0","id":"two","method":"unknown"}`
+	raw := fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n%s"+
+		"Content-Length: %d\r\n\r\n%s", len(initialize), initialize, len(unknown), unknown)
+	if _, err := io.WriteString(client.w, raw); err != nil {
+		t.Fatal(err)
+	}
+	msg := client.read()
+	if string(*msg.ID) != "1" || msg.Error != nil {
+		t.Fatalf("initialize response %s, %v", *msg.ID, msg.Error)
+	}
+	result, _ := msg.Result.(map[string]any)
+	if _, ok := result["capabilities"]; !ok {
+		t.Errorf("initialize result %v has no capabilities", msg.Result)
+	}
+	msg = client.read()
+	if string(*msg.ID) != `"two"` || msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method response %s, %v, want method not found", *msg.ID, msg.Error)
+	}
+
+	// the body length counts bytes, not characters
+	didOpen := `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a","text":"ü teh"}}}`
+	if _, err := fmt.Fprintf(client.w, "Content-Length: %d\r\n\r\n%s", len(didOpen), didOpen); err != nil {
+		t.Fatal(err)
+	}
+	if d := client.diagnostics(); len(d.Diagnostics) != 1 {
+		t.Errorf("got %d diagnostics, want 1", len(d.Diagnostics))
+	}
+
+	// a body that is not JSON is answered with a parse error
+	if _, err := io.WriteString(client.w, "Content-Length: 5\r\n\r\n{nope"); err != nil {
+		t.Fatal(err)
+	}
+	// the ID is null, so is read as nil
+	msg = client.read()
+	if msg.ID != nil || msg.Error == nil || msg.Error.Code != codeParseError {
+		t.Errorf("invalid JSON response %v, %v, want parse error", msg.ID, msg.Error)
+	}
+	client.exit()
+}
+
+func TestDiagnosticRanges(t *testing.T) {
+	client := newTestClient(t, `teh = "the"`)
+	tests := []struct {
+		name string
+		text string
+		want []lspRange
+	}{
+		{
+			name: "ascii",
+			text: "a teh\nteh",
+			want: []lspRange{
+				{Start: position{0, 2}, End: position{0, 5}},
+				{Start: position{1, 0}, End: position{1, 3}},
+			},
+		},
+		{
+			name: "multibyte",
+			text: "naïve teh",
+			want: []lspRange{{Start: position{0, 6}, End: position{0, 9}}},
+		},
+		{
+			// 😀 is outside the Basic Multilingual Plane, so is two UTF-16
+			// code units
+			name: "non-BMP",
+			text: "x\n😀 teh 😀😀 teh",
+			want: []lspRange{
+				{Start: position{1, 3}, End: position{1, 6}},
+				{Start: position{1, 12}, End: position{1, 15}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := "file:///" + tt.name
+			client.open(uri, tt.text)
+			d := client.diagnostics()
+			var got []lspRange
+			for _, diag := range d.Diagnostics {
+				got = append(got, diag.Range)
+				if diag.Data != "the" {
+					t.Errorf("diagnostic correction %q, want the", diag.Data)
+				}
+			}
+			if d.URI != uri || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diagnostics for %s at %v, want %s at %v", d.URI, got, uri, tt.want)
+			}
+		})
+	}
+	client.exit()
+}
+
+func TestCodeActions(t *testing.T) {
+	client := newTestClient(t, `teh = "the"`)
+	client.open("file:///a", "😀 teh teh")
+	client.diagnostics()
+	// a cursor in the second typo
+	cursor := position{0, 8}
+	client.send(1, "textDocument/codeAction", codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: "file:///a"},
+		Range:        lspRange{Start: cursor, End: cursor},
+	})
+	msg := client.read()
+	b, err := json.Marshal(msg.Result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []codeAction
+	if err := json.Unmarshal(b, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("got %d code actions, want 1", len(actions))
+	}
+	want := []textEdit{{Range: lspRange{Start: position{0, 7}, End: position{0, 10}}, NewText: "the"}}
+	if got := actions[0].Edit.Changes["file:///a"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("code action edits %v, want %v", got, want)
+	}
+	client.exit()
+}
+
+func TestRepublishDiagnostics(t *testing.T) {
+	client := newTestClient(t, `teh = "the"`)
+	client.open("file:///a", "teh")
+	client.diagnostics()
+	client.open("file:///b", "teh teh")
+	client.diagnostics()
+	client.send(0, "textDocument/didClose", didCloseParams{TextDocument: textDocumentIdentifier{URI: "file:///b"}})
+	if d := client.diagnostics(); d.URI != "file:///b" || len(d.Diagnostics) != 0 {
+		t.Errorf("closing cleared diagnostics for %s to %v", d.URI, d.Diagnostics)
+	}
+	client.open("file:///c", "teh teh teh")
+	client.diagnostics()
+
+	// as when Watch reloads the corrections
+	go client.server.republishDiagnostics()
+	got := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		d := client.diagnostics()
+		got[d.URI] = len(d.Diagnostics)
+	}
+	if want := map[string]int{"file:///a": 1, "file:///c": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("republished %v, want %v", got, want)
+	}
+	client.exit()
+}
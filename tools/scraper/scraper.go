@@ -23,7 +23,7 @@ var (
 		"0%E2%80%939",
 		"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z",
 	}
-	correctionsFile = "./configs/corrections.toml"
+	correctionsFile = "./configs/corrections.en.toml"
 )
 
 func main() {
@@ -94,14 +94,12 @@ func main() {
 func cleanCorrections(m map[string]string) map[string]string {
 	n := make(map[string]string)
 	removeType := regexp.MustCompile(`\s\[[\w\s]+\]$`)
-	// multipleWord := regexp.MustCompile(`\w+\s\w+`)
 
 	for k, v := range m {
-		// remove mispellings that are multiple words. autocorrector cannot handle
-		// multi-word keys.
-		if strings.ContainsRune(k, ' ') {
-			continue
-		}
+		// normalise the spacing of multi-word mispellings. autocorrector
+		// only matches phrases where the words are separated by a single
+		// space.
+		k = strings.Join(strings.Fields(k), " ")
 		// remove corrections that are just indicating a variant
 		if strings.Contains(v, "variant of") {
 			continue